@@ -0,0 +1,252 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"library/internal/model"
+	"library/internal/service"
+)
+
+// AdminHandler 管理接口处理器
+type AdminHandler struct {
+	catalogSync service.CatalogSyncServiceInterface
+	bookAdmin   service.BookAdminServiceInterface
+	cache       service.RecommendationCacheInterface
+	outbox      service.FeedbackOutboxInterface
+}
+
+// NewAdminHandler 创建新的管理接口处理器，未启用馆藏同步时 catalogSync 为 nil
+func NewAdminHandler(catalogSync service.CatalogSyncServiceInterface, bookAdmin service.BookAdminServiceInterface, cache service.RecommendationCacheInterface, outbox service.FeedbackOutboxInterface) *AdminHandler {
+	return &AdminHandler{
+		catalogSync: catalogSync,
+		bookAdmin:   bookAdmin,
+		cache:       cache,
+		outbox:      outbox,
+	}
+}
+
+// TriggerCatalogSync 手动触发一次馆藏同步，full=true 时同步后将全部馆藏重新发布到Gorse
+func (h *AdminHandler) TriggerCatalogSync(c *gin.Context) {
+	if h.catalogSync == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "馆藏同步未启用"})
+		return
+	}
+
+	full, _ := strconv.ParseBool(c.Query("full"))
+	jobID, err := h.catalogSync.Trigger(model.SyncTriggerManual, full)
+	if errors.Is(err, service.ErrSyncRunning) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":  err.Error(),
+			"job_id": jobID,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "触发馆藏同步失败",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"message": "馆藏同步已开始",
+		"job_id":  jobID,
+	})
+}
+
+// ListSyncRuns 获取馆藏同步的执行记录
+func (h *AdminHandler) ListSyncRuns(c *gin.Context) {
+	if h.catalogSync == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "馆藏同步未启用"})
+		return
+	}
+
+	limit := 20 // 默认返回20条
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 200 {
+			limit = l
+		}
+	}
+
+	runs, err := h.catalogSync.ListRuns(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "获取同步记录失败",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"runs":    runs,
+		"count":   len(runs),
+	})
+}
+
+// InvalidateRecommendationCache 清空热门、最新、相似图书的结果缓存，馆藏同步或批量维护后调用
+func (h *AdminHandler) InvalidateRecommendationCache(c *gin.Context) {
+	cleared := h.cache.InvalidateRecommendationCache()
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "推荐结果缓存已清空",
+		"cleared": cleared,
+	})
+}
+
+// GetOutboxStats 获取反馈发件箱各状态的记录数
+func (h *AdminHandler) GetOutboxStats(c *gin.Context) {
+	stats, err := h.outbox.Stats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "获取发件箱统计失败",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"stats":   stats,
+	})
+}
+
+// RequeueOutbox 将尚未转发到Gorse的行为（包括死信）重新放入发件箱
+func (h *AdminHandler) RequeueOutbox(c *gin.Context) {
+	limit := 1000 // 默认每次重放1000条
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 10000 {
+			limit = l
+		}
+	}
+
+	count, err := h.outbox.RequeuePending(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "重放反馈失败",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"requeued": count,
+	})
+}
+
+// GetBook 获取图书，修改和删除时需回传其中的 updated_at
+func (h *AdminHandler) GetBook(c *gin.Context) {
+	book, err := h.bookAdmin.GetBook(c.Param("id"))
+	if err != nil {
+		respondBookAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"book":    book,
+	})
+}
+
+// CreateBook 新增本地馆藏
+func (h *AdminHandler) CreateBook(c *gin.Context) {
+	var req model.BookCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "请求参数错误",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	book, err := h.bookAdmin.CreateBook(&req)
+	respondBookChange(c, http.StatusCreated, book, err)
+}
+
+// UpdateBook 修改图书，请求中的 updated_at 必须与当前记录一致
+func (h *AdminHandler) UpdateBook(c *gin.Context) {
+	var req model.BookUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "请求参数错误",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	book, err := h.bookAdmin.UpdateBook(c.Param("id"), &req)
+	respondBookChange(c, http.StatusOK, book, err)
+}
+
+// DeleteBook 删除图书，可通过 updated_at 查询参数（RFC3339）进行并发检查
+func (h *AdminHandler) DeleteBook(c *gin.Context) {
+	var updatedAt *time.Time
+	if value := c.Query("updated_at"); value != "" {
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "updated_at 格式错误",
+				"details": err.Error(),
+			})
+			return
+		}
+		updatedAt = &t
+	}
+
+	err := h.bookAdmin.DeleteBook(c.Param("id"), updatedAt)
+	if err != nil && !errors.Is(err, service.ErrGorsePublish) {
+		respondBookAdminError(c, err)
+		return
+	}
+
+	resp := gin.H{
+		"success": true,
+		"message": "图书已删除",
+	}
+	if err != nil {
+		resp["warning"] = err.Error()
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// respondBookChange 输出新增或修改后的图书，数据库已更新但同步Gorse失败时附带警告
+func respondBookChange(c *gin.Context, status int, book *model.BookInfo, err error) {
+	if err != nil && !errors.Is(err, service.ErrGorsePublish) {
+		respondBookAdminError(c, err)
+		return
+	}
+
+	resp := gin.H{
+		"success": true,
+		"book":    book,
+	}
+	if err != nil {
+		resp["warning"] = err.Error()
+	}
+	c.JSON(status, resp)
+}
+
+// respondBookAdminError 将馆藏维护错误映射为HTTP状态码
+func respondBookAdminError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrBookNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidBook):
+		status = http.StatusBadRequest
+	case errors.Is(err, service.ErrBookExists), errors.Is(err, service.ErrBookModified):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{
+		"error":   "图书维护失败",
+		"details": err.Error(),
+	})
+}
@@ -84,7 +84,7 @@ func (h *BehaviorTrackingHandler) GetUserRecommendations(c *gin.Context) {
 		}
 	}
 
-	recommendations, err := h.bookService.GetRecommendations(userID, limit)
+	recommendations, err := h.bookService.GetRecommendations(c.Request.Context(), userID, "", limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -96,6 +96,7 @@ func (h *BehaviorTrackingHandler) GetUserRecommendations(c *gin.Context) {
 		"count":           len(recommendations),
 		"user_id":         userID,
 		"algorithm":       "基于用户行为的协同过滤推荐",
+		"source":          recommendSource(recommendations),
 		"message":         "推荐结果基于您的浏览、点击和停留时间等行为数据生成",
 	})
 }
@@ -109,7 +110,7 @@ func (h *BehaviorTrackingHandler) GetBehaviorBasedPopularBooks(c *gin.Context) {
 		}
 	}
 
-	books, err := h.bookService.GetPopularBooks(limit)
+	books, err := h.bookService.GetPopularBooks(c.Request.Context(), "", limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -120,6 +121,7 @@ func (h *BehaviorTrackingHandler) GetBehaviorBasedPopularBooks(c *gin.Context) {
 		"popular_books": books,
 		"count":         len(books),
 		"algorithm":     "基于用户行为统计的热门度排序",
+		"source":        recommendSource(books),
 		"message":       "热门图书基于所有用户的点击、浏览和停留时间等行为数据统计生成",
 	})
 }
@@ -139,7 +141,13 @@ func (h *BehaviorTrackingHandler) GetBehaviorBasedSimilarBooks(c *gin.Context) {
 		}
 	}
 
-	books, err := h.bookService.GetSimilarBooks(title, limit)
+	bookID, err := h.bookService.ResolveBookID("", title)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	books, err := h.bookService.GetSimilarBooks(c.Request.Context(), bookID, "", limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -151,6 +159,7 @@ func (h *BehaviorTrackingHandler) GetBehaviorBasedSimilarBooks(c *gin.Context) {
 		"count":         len(books),
 		"base_title":    title,
 		"algorithm":     "基于用户行为的物品协同过滤",
+		"source":        recommendSource(books),
 		"message":       "相似图书基于用户对图书的行为模式相似性推荐",
 	})
 }
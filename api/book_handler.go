@@ -108,7 +108,7 @@ func (h *BookHandler) GetRecommendations(c *gin.Context) {
 		}
 	}
 
-	recommendations, err := h.bookService.GetRecommendations(userID, limit)
+	recommendations, err := h.bookService.GetRecommendations(c.Request.Context(), userID, "", limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -130,7 +130,7 @@ func (h *BookHandler) GetPopularBooks(c *gin.Context) {
 		}
 	}
 
-	books, err := h.bookService.GetPopularBooks(limit)
+	books, err := h.bookService.GetPopularBooks(c.Request.Context(), "", limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -158,7 +158,13 @@ func (h *BookHandler) GetSimilarBooks(c *gin.Context) {
 		}
 	}
 
-	books, err := h.bookService.GetSimilarBooks(title, limit)
+	bookID, err := h.bookService.ResolveBookID("", title)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	books, err := h.bookService.GetSimilarBooks(c.Request.Context(), bookID, "", limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -1,10 +1,13 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"library/internal/model"
 	"library/internal/service"
 )
 
@@ -44,10 +47,51 @@ func (h *UnifiedHandler) TrackUserBehavior(c *gin.Context) {
 		"message":       "用户行为记录成功",
 		"behavior_type": req.BehaviorType,
 		"user_id":       req.UserID,
+		"book_id":       req.BookID,
 		"book_title":    req.BookTitle,
 	})
 }
 
+// maxBatchBehaviors 单次批量上报的最大行为条数
+const maxBatchBehaviors = 1000
+
+// BatchTrackUserBehavior 批量用户行为上报（供离线终端、移动端缓存后统一上传）
+func (h *UnifiedHandler) BatchTrackUserBehavior(c *gin.Context) {
+	var req struct {
+		Behaviors []*service.UserBehaviorRequest `json:"behaviors" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "参数错误",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if len(req.Behaviors) == 0 || len(req.Behaviors) > maxBatchBehaviors {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("behaviors 数量必须在 1 到 %d 之间", maxBatchBehaviors),
+		})
+		return
+	}
+
+	result, err := h.bookService.RecordUserBehaviors(req.Behaviors)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "批量记录用户行为失败",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": result.Failed == 0,
+		"message": fmt.Sprintf("成功记录 %d 条，失败 %d 条", result.Succeeded, result.Failed),
+		"result":  result,
+	})
+}
+
 // GetPersonalizedRecommendations 获取个性化推荐
 func (h *UnifiedHandler) GetPersonalizedRecommendations(c *gin.Context) {
 	userID := c.Query("user_id")
@@ -65,7 +109,12 @@ func (h *UnifiedHandler) GetPersonalizedRecommendations(c *gin.Context) {
 		}
 	}
 
-	recommendations, err := h.bookService.GetRecommendations(userID, limit)
+	category, ok := parseCategory(c)
+	if !ok {
+		return
+	}
+
+	recommendations, err := h.bookService.GetRecommendations(c.Request.Context(), userID, category, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "获取推荐失败",
@@ -79,10 +128,20 @@ func (h *UnifiedHandler) GetPersonalizedRecommendations(c *gin.Context) {
 		"recommendations": recommendations,
 		"count":           len(recommendations),
 		"user_id":         userID,
+		"category":        category,
 		"algorithm":       "基于用户行为的协同过滤推荐",
+		"source":          recommendSource(recommendations),
 	})
 }
 
+// recommendSource 推荐结果使用的推荐源，结果为空时为空字符串
+func recommendSource(books []*model.RecommendedBook) model.RecommendSource {
+	if len(books) == 0 {
+		return ""
+	}
+	return books[0].Source
+}
+
 // GetPopularBooks 获取热门图书
 func (h *UnifiedHandler) GetPopularBooks(c *gin.Context) {
 	limit := 10 // 默认返回10本
@@ -92,7 +151,12 @@ func (h *UnifiedHandler) GetPopularBooks(c *gin.Context) {
 		}
 	}
 
-	books, err := h.bookService.GetPopularBooks(limit)
+	category, ok := parseCategory(c)
+	if !ok {
+		return
+	}
+
+	books, err := h.bookService.GetPopularBooks(c.Request.Context(), category, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "获取热门图书失败",
@@ -105,16 +169,29 @@ func (h *UnifiedHandler) GetPopularBooks(c *gin.Context) {
 		"success":       true,
 		"popular_books": books,
 		"count":         len(books),
+		"category":      category,
 		"algorithm":     "基于用户行为统计的热门度排序",
+		"source":        recommendSource(books),
 	})
 }
 
 // GetSimilarBooks 获取相似图书
 func (h *UnifiedHandler) GetSimilarBooks(c *gin.Context) {
+	bookID := c.Query("book_id")
 	title := c.Query("title")
-	if title == "" {
+	if bookID == "" && title == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "title 参数是必需的",
+			"error": "book_id 或 title 参数是必需的",
+		})
+		return
+	}
+
+	// 兼容仅传标题的旧客户端
+	bookID, err := h.bookService.ResolveBookID(bookID, title)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "图书不存在",
+			"details": err.Error(),
 		})
 		return
 	}
@@ -126,7 +203,12 @@ func (h *UnifiedHandler) GetSimilarBooks(c *gin.Context) {
 		}
 	}
 
-	books, err := h.bookService.GetSimilarBooks(title, limit)
+	category, ok := parseCategory(c)
+	if !ok {
+		return
+	}
+
+	books, err := h.bookService.GetSimilarBooks(c.Request.Context(), bookID, category, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "获取相似图书失败",
@@ -139,17 +221,192 @@ func (h *UnifiedHandler) GetSimilarBooks(c *gin.Context) {
 		"success":       true,
 		"similar_books": books,
 		"count":         len(books),
+		"base_book_id":  bookID,
 		"base_title":    title,
+		"category":      category,
 		"algorithm":     "基于用户行为的物品协同过滤",
+		"source":        recommendSource(books),
+	})
+}
+
+// GetHomePage 获取首页多栏目推荐，user_id 可选，未提供时只返回非个性化栏目
+func (h *UnifiedHandler) GetHomePage(c *gin.Context) {
+	userID := c.Query("user_id")
+
+	limit := 10 // 每个栏目默认10本
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 50 {
+			limit = l
+		}
+	}
+
+	category, ok := parseCategory(c)
+	if !ok {
+		return
+	}
+
+	shelves, err := h.bookService.GetHomeShelves(c.Request.Context(), userID, category, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "获取首页推荐失败",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"shelves": shelves,
+		"count":   len(shelves),
+		"user_id": userID,
+	})
+}
+
+// ListCategories 获取可用的推荐分类（中图法一级大类及二级类目）及馆藏数量
+func (h *UnifiedHandler) ListCategories(c *gin.Context) {
+	categories, err := h.bookService.ListCategories()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "获取推荐分类失败",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"categories": categories,
+		"count":      len(categories),
+	})
+}
+
+// parseCategory 解析并校验 category 查询参数，非法时直接写入400响应
+func parseCategory(c *gin.Context) (string, bool) {
+	raw := c.Query("category")
+	if raw == "" {
+		return "", true
+	}
+
+	category, ok := model.NormalizeCLCCategory(raw)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "category 必须是中图法一级大类（如 I）或二级类目（如 TP）代码",
+		})
+		return "", false
+	}
+	return category, true
+}
+
+// SearchBooks 馆藏检索，支持关键词和分类、语种、出版社、出版年份筛选
+func (h *UnifiedHandler) SearchBooks(c *gin.Context) {
+	var query model.BookSearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "参数错误",
+			"details": err.Error(),
+		})
+		return
+	}
+	if query.Q == "" && query.Classification == "" && query.Language == "" && query.Publisher == "" && query.Year == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "q、classification、language、publisher、year 至少需要提供一个",
+		})
+		return
+	}
+
+	result, err := h.bookService.SearchBooks(&query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "检索图书失败",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"result":  result,
+	})
+}
+
+// GetBookDetail 获取图书详情，:id 为图书编号（兼容数字主键）
+func (h *UnifiedHandler) GetBookDetail(c *gin.Context) {
+	detail, err := h.bookService.GetBookDetail(c.Request.Context(), c.Param("id"))
+	respondBookDetail(c, detail, err)
+}
+
+// GetBookDetailByBarcode 按条形码获取图书详情
+func (h *UnifiedHandler) GetBookDetailByBarcode(c *gin.Context) {
+	detail, err := h.bookService.GetBookDetailByBarcode(c.Request.Context(), c.Param("barcode"))
+	respondBookDetail(c, detail, err)
+}
+
+// respondBookDetail 输出图书详情，图书不存在时返回404
+func respondBookDetail(c *gin.Context, detail *model.BookDetail, err error) {
+	if errors.Is(err, service.ErrBookNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "图书不存在",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "获取图书详情失败",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"book":    detail,
+	})
+}
+
+// maxBatchBookIDs 批量查询单次最多的图书编号数
+const maxBatchBookIDs = 500
+
+// BatchGetBooks 按图书编号分页批量获取图书
+func (h *UnifiedHandler) BatchGetBooks(c *gin.Context) {
+	var req model.BatchBookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "参数错误",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if len(req.BookIDs) == 0 || len(req.BookIDs) > maxBatchBookIDs {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("book_ids 数量必须在 1 到 %d 之间", maxBatchBookIDs),
+		})
+		return
+	}
+
+	result, err := h.bookService.BatchGetBooks(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "批量获取图书失败",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"result":  result,
 	})
 }
 
 // HealthCheck 健康检查
 func (h *UnifiedHandler) HealthCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
-		"status":  "healthy",
-		"service": "library-recommendation-system",
-		"version": "1.0.0",
+		"status":      "healthy",
+		"service":     "library-recommendation-system",
+		"version":     "1.0.0",
+		"recommender": h.bookService.RecommenderStatus(),
 	})
 }
 
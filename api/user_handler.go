@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"library/internal/service"
+)
+
+// UserHandler 读者行为历史与兴趣分析处理器
+type UserHandler struct {
+	behaviorService service.BehaviorTrackingServiceInterface
+}
+
+// NewUserHandler 创建新的读者处理器
+func NewUserHandler(behaviorService service.BehaviorTrackingServiceInterface) *UserHandler {
+	return &UserHandler{behaviorService: behaviorService}
+}
+
+// GetUserHistory 获取读者的行为历史
+func (h *UserHandler) GetUserHistory(c *gin.Context) {
+	userID := c.Param("id")
+
+	limit := 20 // 默认返回20条
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 200 {
+			limit = l
+		}
+	}
+
+	history, err := h.behaviorService.GetUserBehaviorHistory(userID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "获取行为历史失败",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"history": history,
+		"count":   len(history),
+		"user_id": userID,
+	})
+}
+
+// GetUserInterests 获取读者的兴趣分析结果
+func (h *UserHandler) GetUserInterests(c *gin.Context) {
+	userID := c.Param("id")
+
+	interests, err := h.behaviorService.AnalyzeUserInterests(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "分析用户兴趣失败",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"interests": interests,
+		"count":     len(interests),
+		"user_id":   userID,
+		"algorithm": "基于行为权重与时间衰减的分类号、作者、出版社偏好统计",
+	})
+}
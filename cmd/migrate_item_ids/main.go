@@ -0,0 +1,190 @@
+// migrate_item_ids 一次性迁移工具：将Gorse中以图书标题为物品ID的反馈改写为以图书编号(book_id)为物品ID，
+// 并为 user_behaviors、feedback_outbox 中的历史记录补全图书编号。
+//
+// 用法：go run ./cmd/migrate_item_ids [-dry-run] [-page-size 1000]
+package main
+
+import (
+	"flag"
+	"log"
+
+	"library/config"
+	"library/internal/gorse"
+	"library/internal/model"
+	"library/internal/repository"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "只统计需要迁移的反馈，不写入Gorse和数据库")
+	pageSize := flag.Int("page-size", 1000, "每次从Gorse导出的反馈条数")
+	flag.Parse()
+
+	cfg := config.NewConfig()
+
+	db, err := gorm.Open(postgres.Open(cfg.Database.DSN()), &gorm.Config{})
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	bookRepo := repository.NewBookRepository(db)
+	behaviorRepo := repository.NewBehaviorRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	client := gorse.NewClient(cfg.Gorse.Endpoint, cfg.Gorse.APIKey)
+
+	// 先完整导出，避免边遍历边改写导致游标错乱
+	var feedbacks []gorse.Feedback
+	cursor := ""
+	for {
+		next, page, err := client.ListFeedback(cursor, *pageSize)
+		if err != nil {
+			log.Fatalf("导出Gorse反馈失败: %v", err)
+		}
+		feedbacks = append(feedbacks, page...)
+		if next == "" || len(page) == 0 {
+			break
+		}
+		cursor = next
+	}
+	log.Printf("共导出 %d 条反馈", len(feedbacks))
+
+	mapping, err := resolveTitles(bookRepo, feedbacks)
+	if err != nil {
+		log.Fatalf("解析图书编号失败: %v", err)
+	}
+
+	var migrated []gorse.Feedback
+	unresolved := make(map[string]struct{})
+	for _, feedback := range feedbacks {
+		bookID, ok := mapping[feedback.ItemId]
+		if !ok {
+			unresolved[feedback.ItemId] = struct{}{}
+			continue
+		}
+		if bookID == feedback.ItemId {
+			continue // 已是图书编号
+		}
+		migrated = append(migrated, feedback)
+	}
+	log.Printf("需迁移 %d 条反馈，%d 个物品ID无法匹配馆藏记录", len(migrated), len(unresolved))
+	for itemID := range unresolved {
+		log.Printf("无法匹配: %q", itemID)
+	}
+
+	if *dryRun {
+		log.Println("dry-run 模式，未做任何修改")
+		return
+	}
+
+	// 全部写入新ID的反馈后再删除旧反馈，中途失败可重新运行
+	for start := 0; start < len(migrated); start += *pageSize {
+		end := start + *pageSize
+		if end > len(migrated) {
+			end = len(migrated)
+		}
+
+		batch := make([]gorse.Feedback, 0, end-start)
+		for _, feedback := range migrated[start:end] {
+			feedback.ItemId = mapping[feedback.ItemId]
+			batch = append(batch, feedback)
+		}
+		if err := client.InsertFeedbacks(batch); err != nil {
+			log.Fatalf("写入迁移后的反馈失败: %v", err)
+		}
+		log.Printf("已写入 %d/%d 条反馈", end, len(migrated))
+	}
+
+	// DeleteFeedback 删除用户与物品之间的全部类型反馈，每个组合只需删除一次
+	type feedbackPair struct {
+		userID string
+		itemID string
+	}
+	pairs := make(map[feedbackPair]struct{})
+	oldItems := make(map[string]struct{})
+	for _, feedback := range migrated {
+		pairs[feedbackPair{userID: feedback.UserId, itemID: feedback.ItemId}] = struct{}{}
+		oldItems[feedback.ItemId] = struct{}{}
+	}
+	for pair := range pairs {
+		if err := client.DeleteFeedback(pair.userID, pair.itemID); err != nil {
+			log.Printf("删除旧反馈 %s/%s 失败: %v", pair.userID, pair.itemID, err)
+		}
+	}
+	log.Printf("已删除 %d 组旧反馈", len(pairs))
+
+	for itemID := range oldItems {
+		if err := client.DeleteItem(itemID); err != nil {
+			log.Printf("删除旧物品 %q 失败: %v", itemID, err)
+		}
+	}
+
+	backfilled, err := behaviorRepo.BackfillBookIDs()
+	if err != nil {
+		log.Fatalf("补全行为记录图书编号失败: %v", err)
+	}
+	synced, err := outboxRepo.SyncItemIDs()
+	if err != nil {
+		log.Fatalf("同步发件箱物品ID失败: %v", err)
+	}
+
+	log.Printf("迁移完成：反馈 %d 条，删除旧物品 %d 个，补全行为记录 %d 条，同步发件箱 %d 条",
+		len(migrated), len(oldItems), backfilled, synced)
+}
+
+// resolveTitles 将反馈中的物品ID映射为图书编号：已是图书编号的映射为自身，标题映射为最早入库的同名图书
+func resolveTitles(bookRepo repository.BookRepository, feedbacks []gorse.Feedback) (map[string]string, error) {
+	seen := make(map[string]struct{})
+	itemIDs := make([]string, 0)
+	for _, feedback := range feedbacks {
+		if _, ok := seen[feedback.ItemId]; !ok {
+			seen[feedback.ItemId] = struct{}{}
+			itemIDs = append(itemIDs, feedback.ItemId)
+		}
+	}
+
+	mapping := make(map[string]string, len(itemIDs))
+	const chunk = 1000
+	for start := 0; start < len(itemIDs); start += chunk {
+		end := start + chunk
+		if end > len(itemIDs) {
+			end = len(itemIDs)
+		}
+		ids := itemIDs[start:end]
+
+		books, err := bookRepo.FindByBookIDs(ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, book := range books {
+			mapping[book.BookID] = book.BookID
+		}
+
+		titles := make([]string, 0, len(ids))
+		for _, id := range ids {
+			if _, ok := mapping[id]; !ok {
+				titles = append(titles, id)
+			}
+		}
+		if len(titles) == 0 {
+			continue
+		}
+
+		byTitle, _, err := bookRepo.BatchGetBooksByTitles(titles)
+		if err != nil {
+			return nil, err
+		}
+		earliest := make(map[string]*model.BookInfo, len(byTitle))
+		for _, book := range byTitle {
+			if existing, ok := earliest[book.Title]; !ok || book.ID.LessThan(existing.ID) {
+				earliest[book.Title] = book
+			}
+		}
+		for title, book := range earliest {
+			mapping[title] = book.BookID
+		}
+	}
+
+	return mapping, nil
+}
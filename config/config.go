@@ -1,16 +1,29 @@
 package config
 
 import (
+	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"library/internal/scheduler"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
-	Gorse    GorseConfig
+	Database    DatabaseConfig
+	Server      ServerConfig
+	Gorse       GorseConfig
+	Outbox      OutboxConfig
+	Recommend   RecommendConfig
+	Catalog     CatalogConfig
+	Circulation CirculationConfig
+	Admin       AdminConfig
+	Fallback    FallbackConfig
 }
 
 type ServerConfig struct {
@@ -26,9 +39,228 @@ type DatabaseConfig struct {
 	SSLMode  string
 }
 
+// DSN 构建 PostgreSQL 连接字符串
+func (c DatabaseConfig) DSN() string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		c.Host,
+		c.Port,
+		c.User,
+		c.Password,
+		c.DBName,
+		c.SSLMode,
+	)
+}
+
 type GorseConfig struct {
-	Endpoint string
-	APIKey   string
+	Endpoint         string
+	APIKey           string
+	RequestTimeout   time.Duration // 单次请求的整体超时
+	FeedbackTimeout  time.Duration // 写入反馈的超时
+	RecommendTimeout time.Duration // 个性化推荐的超时
+	PopularTimeout   time.Duration // 热门图书的超时
+	NeighborsTimeout time.Duration // 相似图书的超时
+	LatestTimeout    time.Duration // 最新图书的超时
+	MaxRetries       int           // 读请求遇到网络错误、5xx 或429时的重试次数
+	RetryBackoff     time.Duration // 首次重试的退避时间
+	BreakerThreshold int           // 连续失败多少次后熔断，为0时不熔断
+	BreakerCooldown  time.Duration // 熔断后经过多久放行探测请求
+}
+
+// OutboxConfig 反馈发件箱投递配置
+type OutboxConfig struct {
+	BatchSize    int           // 每次投递的最大条数
+	PollInterval time.Duration // 轮询间隔
+	MaxAttempts  int           // 最大重试次数，超过后进入死信
+	BaseBackoff  time.Duration // 首次重试退避时间
+	MaxBackoff   time.Duration // 最大退避时间
+	DrainTimeout time.Duration // 关闭时排空发件箱的最长等待时间
+}
+
+// RecommendConfig 推荐结果过滤配置
+type RecommendConfig struct {
+	ExcludeStatuses  []string      // 直接从推荐结果中剔除的图书状态
+	DownrankStatuses []string      // 排到可借图书之后的图书状态
+	OverfetchFactor  int           // 每轮向Gorse请求 limit*OverfetchFactor 个候选
+	MaxFetchRounds   int           // 过滤后不足 limit 时最多向Gorse补取的轮数
+	ShelfTimeout     time.Duration // 首页单个栏目的超时时间
+	Backend          string        // 推荐引擎：gorse、local、failover（Gorse 为主，不可用时改用本地）、blend（两者按权重融合）
+	BlendWeight      float64       // blend 模式下 Gorse 结果的权重（0~1），其余权重给本地推荐
+	PopularCacheTTL  time.Duration // 热门图书结果的缓存时间，0 表示不缓存
+	LatestCacheTTL   time.Duration // 最新图书结果的缓存时间，0 表示不缓存
+	SimilarCacheTTL  time.Duration // 相似图书结果的缓存时间，0 表示不缓存
+	CacheMaxEntries  int           // 推荐结果缓存最多保留的条目数
+}
+
+// 推荐引擎
+const (
+	RecommendBackendGorse    = "gorse"
+	RecommendBackendLocal    = "local"
+	RecommendBackendFailover = "failover"
+	RecommendBackendBlend    = "blend"
+)
+
+// UsesLocal 所选推荐引擎是否需要本地推荐模型
+func (c RecommendConfig) UsesLocal() bool {
+	return c.Backend != RecommendBackendGorse
+}
+
+// Validate 校验推荐引擎配置
+func (c RecommendConfig) Validate() error {
+	switch c.Backend {
+	case RecommendBackendGorse, RecommendBackendLocal, RecommendBackendFailover, RecommendBackendBlend:
+	default:
+		return fmt.Errorf("RECOMMEND_BACKEND 必须为 gorse、local、failover 或 blend")
+	}
+	if c.ShelfTimeout <= 0 {
+		return fmt.Errorf("RECOMMEND_SHELF_TIMEOUT 必须大于0")
+	}
+	if c.BlendWeight < 0 || c.BlendWeight > 1 {
+		return fmt.Errorf("RECOMMEND_BLEND_WEIGHT 必须在0到1之间")
+	}
+	if c.PopularCacheTTL < 0 || c.LatestCacheTTL < 0 || c.SimilarCacheTTL < 0 {
+		return fmt.Errorf("RECOMMEND_POPULAR_CACHE_TTL、RECOMMEND_LATEST_CACHE_TTL、RECOMMEND_SIMILAR_CACHE_TTL 不能为负数")
+	}
+	if c.CacheMaxEntries <= 0 {
+		return fmt.Errorf("RECOMMEND_CACHE_MAX_ENTRIES 必须大于0")
+	}
+	return nil
+}
+
+// CatalogConfig 上游馆藏数据平台配置
+type CatalogConfig struct {
+	BaseURL            string        // 数据平台地址，如 https://222.204.7.196:33027
+	TokenPath          string        // 获取token的接口路径
+	BooksPath          string        // 图书基本信息接口路径
+	AppID              string        // 数据平台分配的 AppId，为空时不启用馆藏同步
+	AppCode            int           // 数据平台分配的 code
+	StartPage          int           // 起始页码
+	PageSize           int           // 每页条数
+	RequestTimeout     time.Duration // 单次请求超时时间
+	CAFile             string        // 自定义CA证书文件（PEM），为空时使用系统证书
+	InsecureSkipVerify bool          // 跳过TLS证书校验，仅用于测试环境
+	PageRetries        int           // 单页请求失败后的重试次数
+	RetryBackoff       time.Duration // 首次重试的退避时间，之后按指数增长
+	RemovalMode        string        // 上游已删除记录的处理方式：lost 标记为丢失，delete 软删除
+	MaxRemovalRatio    float64       // 单次同步允许处理的删除记录占比上限，超过时视为上游数据异常而跳过
+	SyncSchedule       string        // 定时同步的 cron 表达式（分 时 日 月 周），如 "0 3 * * *"
+	TokenRefreshMargin time.Duration // token 到期前提前刷新的时间
+}
+
+// 上游已删除记录的处理方式
+const (
+	CatalogRemovalLost   = "lost"
+	CatalogRemovalDelete = "delete"
+)
+
+// FallbackConfig 本地推荐配置，根据 user_behaviors 计算推荐，RECOMMEND_BACKEND 为 gorse 时不使用
+type FallbackConfig struct {
+	Window          time.Duration // 参与计算的行为时间窗口
+	HalfLife        time.Duration // 行为权重的衰减半衰期
+	HistorySize     int           // 个性化推荐参考的读者最近交互图书数
+	RefreshSchedule string        // 重新计算的 cron 表达式
+}
+
+// Validate 校验本地推荐配置
+func (c FallbackConfig) Validate() error {
+	if c.Window <= 0 || c.HalfLife <= 0 {
+		return fmt.Errorf("FALLBACK_WINDOW 和 FALLBACK_HALF_LIFE 必须大于0")
+	}
+	if c.HistorySize <= 0 {
+		return fmt.Errorf("FALLBACK_HISTORY_SIZE 必须大于0")
+	}
+	if _, err := scheduler.Parse(c.RefreshSchedule); err != nil {
+		return fmt.Errorf("FALLBACK_REFRESH_SCHEDULE 无效: %v", err)
+	}
+	return nil
+}
+
+// AdminConfig 管理接口配置
+type AdminConfig struct {
+	Token string // 管理接口访问令牌，请求需携带 Authorization: Bearer <token>；为空时管理接口不可用
+}
+
+// CirculationConfig 流通（借还）记录导入配置，接口地址和鉴权沿用 CatalogConfig
+type CirculationConfig struct {
+	Path         string        // 数据平台流通记录接口路径，为空时不启用定时导入（仍可通过命令行导入CSV）
+	PageSize     int           // 每页条数
+	SyncSchedule string        // 定时导入的 cron 表达式
+	PageRetries  int           // 单页请求失败后的重试次数
+	RetryBackoff time.Duration // 首次重试的退避时间，之后按指数增长
+	SyncOverlap  time.Duration // 增量同步起点向前回溯的时间，容忍上游写入延迟和时钟偏差
+}
+
+// Enabled 是否启用流通记录定时导入
+func (c CirculationConfig) Enabled() bool {
+	return c.Path != ""
+}
+
+// Validate 校验流通记录导入配置
+func (c CirculationConfig) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	if !strings.HasPrefix(c.Path, "/") {
+		return fmt.Errorf("CIRCULATION_PATH 必须以 / 开头")
+	}
+	if c.PageSize <= 0 {
+		return fmt.Errorf("CIRCULATION_PAGE_SIZE 必须大于0")
+	}
+	if c.PageRetries < 0 || c.RetryBackoff <= 0 {
+		return fmt.Errorf("CIRCULATION_PAGE_RETRIES 不能为负数，CIRCULATION_RETRY_BACKOFF 必须大于0")
+	}
+	if c.SyncOverlap < 0 {
+		return fmt.Errorf("CIRCULATION_SYNC_OVERLAP 不能为负数")
+	}
+	if _, err := scheduler.Parse(c.SyncSchedule); err != nil {
+		return fmt.Errorf("CIRCULATION_SYNC_SCHEDULE 无效: %v", err)
+	}
+	return nil
+}
+
+// Enabled 是否启用馆藏同步
+func (c CatalogConfig) Enabled() bool {
+	return c.AppID != ""
+}
+
+// Validate 校验馆藏数据平台配置
+func (c CatalogConfig) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	u, err := url.Parse(c.BaseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("CATALOG_BASE_URL 无效: %q", c.BaseURL)
+	}
+	if !strings.HasPrefix(c.TokenPath, "/") || !strings.HasPrefix(c.BooksPath, "/") {
+		return fmt.Errorf("CATALOG_TOKEN_PATH 和 CATALOG_BOOKS_PATH 必须以 / 开头")
+	}
+	if c.StartPage <= 0 || c.PageSize <= 0 {
+		return fmt.Errorf("CATALOG_START_PAGE 和 CATALOG_PAGE_SIZE 必须大于0")
+	}
+	if c.RequestTimeout <= 0 {
+		return fmt.Errorf("CATALOG_REQUEST_TIMEOUT 必须大于0")
+	}
+	if c.TokenRefreshMargin < 0 {
+		return fmt.Errorf("CATALOG_TOKEN_REFRESH_MARGIN 不能为负数")
+	}
+	if c.PageRetries < 0 || c.RetryBackoff <= 0 {
+		return fmt.Errorf("CATALOG_PAGE_RETRIES 不能为负数，CATALOG_RETRY_BACKOFF 必须大于0")
+	}
+	if c.RemovalMode != CatalogRemovalLost && c.RemovalMode != CatalogRemovalDelete {
+		return fmt.Errorf("CATALOG_REMOVAL_MODE 只能为 %s 或 %s", CatalogRemovalLost, CatalogRemovalDelete)
+	}
+	if c.MaxRemovalRatio < 0 || c.MaxRemovalRatio > 1 {
+		return fmt.Errorf("CATALOG_MAX_REMOVAL_RATIO 必须在 0 到 1 之间")
+	}
+	if _, err := scheduler.Parse(c.SyncSchedule); err != nil {
+		return fmt.Errorf("CATALOG_SYNC_SCHEDULE 无效: %v", err)
+	}
+	if c.CAFile != "" {
+		if _, err := os.Stat(c.CAFile); err != nil {
+			return fmt.Errorf("CATALOG_CA_FILE 无法读取: %v", err)
+		}
+	}
+	return nil
 }
 
 // getEnv 从环境变量获取值，如果环境变量不存在则返回默认值
@@ -39,6 +271,74 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// defaultRecommendBackend 未设置 RECOMMEND_BACKEND 时的推荐引擎
+// 兼容旧配置：FALLBACK_ENABLED=false 表示只使用Gorse
+func defaultRecommendBackend() string {
+	if getEnvBool("FALLBACK_ENABLED", true) {
+		return RecommendBackendFailover
+	}
+	return RecommendBackendGorse
+}
+
+// getEnvInt 从环境变量获取整数值，不存在或无法解析时返回默认值
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+		log.Printf("警告: 环境变量 %s=%q 不是有效整数，使用默认值 %d", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// getEnvFloat 从环境变量获取浮点数，不存在或无法解析时返回默认值
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+		log.Printf("警告: 环境变量 %s=%q 不是有效数字，使用默认值 %g", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// getEnvDuration 从环境变量获取时长（如 "30s"、"5m"），不存在或无法解析时返回默认值
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+		log.Printf("警告: 环境变量 %s=%q 不是有效时长，使用默认值 %s", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// getEnvBool 从环境变量获取布尔值，不存在或无法解析时返回默认值
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+		log.Printf("警告: 环境变量 %s=%q 不是有效布尔值，使用默认值 %t", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// getEnvList 从环境变量获取逗号分隔的列表，不存在时返回默认值
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 // LoadEnv 加载环境变量文件
 func LoadEnv() {
 	err := godotenv.Load()
@@ -64,8 +364,74 @@ func NewConfig() *Config {
 			Port: getEnv("SERVER_PORT", "8080"),
 		},
 		Gorse: GorseConfig{
-			Endpoint: getEnv("GORSE_ENDPOINT", "http://localhost:8088"),
-			APIKey:   getEnv("GORSE_API_KEY", ""),
+			Endpoint:         getEnv("GORSE_ENDPOINT", "http://localhost:8088"),
+			APIKey:           getEnv("GORSE_API_KEY", ""),
+			RequestTimeout:   getEnvDuration("GORSE_REQUEST_TIMEOUT", 10*time.Second),
+			FeedbackTimeout:  getEnvDuration("GORSE_FEEDBACK_TIMEOUT", 5*time.Second),
+			RecommendTimeout: getEnvDuration("GORSE_RECOMMEND_TIMEOUT", 3*time.Second),
+			PopularTimeout:   getEnvDuration("GORSE_POPULAR_TIMEOUT", 2*time.Second),
+			NeighborsTimeout: getEnvDuration("GORSE_NEIGHBORS_TIMEOUT", 2*time.Second),
+			LatestTimeout:    getEnvDuration("GORSE_LATEST_TIMEOUT", 2*time.Second),
+			MaxRetries:       getEnvInt("GORSE_MAX_RETRIES", 2),
+			RetryBackoff:     getEnvDuration("GORSE_RETRY_BACKOFF", 100*time.Millisecond),
+			BreakerThreshold: getEnvInt("GORSE_BREAKER_THRESHOLD", 5),
+			BreakerCooldown:  getEnvDuration("GORSE_BREAKER_COOLDOWN", 30*time.Second),
+		},
+		Outbox: OutboxConfig{
+			BatchSize:    getEnvInt("OUTBOX_BATCH_SIZE", 200),
+			PollInterval: getEnvDuration("OUTBOX_POLL_INTERVAL", 2*time.Second),
+			MaxAttempts:  getEnvInt("OUTBOX_MAX_ATTEMPTS", 10),
+			BaseBackoff:  getEnvDuration("OUTBOX_BASE_BACKOFF", 2*time.Second),
+			MaxBackoff:   getEnvDuration("OUTBOX_MAX_BACKOFF", 30*time.Minute),
+			DrainTimeout: getEnvDuration("OUTBOX_DRAIN_TIMEOUT", 10*time.Second),
+		},
+		Recommend: RecommendConfig{
+			ExcludeStatuses:  getEnvList("RECOMMEND_EXCLUDE_STATUSES", []string{"lost", "damaged", "maintenance"}),
+			DownrankStatuses: getEnvList("RECOMMEND_DOWNRANK_STATUSES", []string{"borrowed", "reserved"}),
+			OverfetchFactor:  getEnvInt("RECOMMEND_OVERFETCH_FACTOR", 2),
+			MaxFetchRounds:   getEnvInt("RECOMMEND_MAX_FETCH_ROUNDS", 3),
+			ShelfTimeout:     getEnvDuration("RECOMMEND_SHELF_TIMEOUT", 2*time.Second),
+			Backend:          getEnv("RECOMMEND_BACKEND", defaultRecommendBackend()),
+			BlendWeight:      getEnvFloat("RECOMMEND_BLEND_WEIGHT", 0.7),
+			PopularCacheTTL:  getEnvDuration("RECOMMEND_POPULAR_CACHE_TTL", 5*time.Minute),
+			LatestCacheTTL:   getEnvDuration("RECOMMEND_LATEST_CACHE_TTL", 10*time.Minute),
+			SimilarCacheTTL:  getEnvDuration("RECOMMEND_SIMILAR_CACHE_TTL", 30*time.Minute),
+			CacheMaxEntries:  getEnvInt("RECOMMEND_CACHE_MAX_ENTRIES", 10000),
+		},
+		Catalog: CatalogConfig{
+			BaseURL:            getEnv("CATALOG_BASE_URL", "https://222.204.7.196:33027"),
+			TokenPath:          getEnv("CATALOG_TOKEN_PATH", "/api/token/getToken"),
+			BooksPath:          getEnv("CATALOG_BOOKS_PATH", "/api/dwd_ryy_xszj_tsjbxxzlb"),
+			AppID:              getEnv("CATALOG_APP_ID", ""),
+			AppCode:            getEnvInt("CATALOG_APP_CODE", 0),
+			StartPage:          getEnvInt("CATALOG_START_PAGE", 1),
+			PageSize:           getEnvInt("CATALOG_PAGE_SIZE", 1000),
+			RequestTimeout:     getEnvDuration("CATALOG_REQUEST_TIMEOUT", 30*time.Second),
+			CAFile:             getEnv("CATALOG_CA_FILE", ""),
+			InsecureSkipVerify: getEnvBool("CATALOG_INSECURE_SKIP_VERIFY", false),
+			PageRetries:        getEnvInt("CATALOG_PAGE_RETRIES", 3),
+			RetryBackoff:       getEnvDuration("CATALOG_RETRY_BACKOFF", 2*time.Second),
+			RemovalMode:        getEnv("CATALOG_REMOVAL_MODE", CatalogRemovalLost),
+			MaxRemovalRatio:    getEnvFloat("CATALOG_MAX_REMOVAL_RATIO", 0.2),
+			SyncSchedule:       getEnv("CATALOG_SYNC_SCHEDULE", "0 3 * * *"),
+			TokenRefreshMargin: getEnvDuration("CATALOG_TOKEN_REFRESH_MARGIN", time.Minute),
+		},
+		Circulation: CirculationConfig{
+			Path:         getEnv("CIRCULATION_PATH", ""),
+			PageSize:     getEnvInt("CIRCULATION_PAGE_SIZE", 1000),
+			SyncSchedule: getEnv("CIRCULATION_SYNC_SCHEDULE", "*/30 * * * *"),
+			PageRetries:  getEnvInt("CIRCULATION_PAGE_RETRIES", 3),
+			RetryBackoff: getEnvDuration("CIRCULATION_RETRY_BACKOFF", 2*time.Second),
+			SyncOverlap:  getEnvDuration("CIRCULATION_SYNC_OVERLAP", time.Hour),
+		},
+		Admin: AdminConfig{
+			Token: getEnv("ADMIN_TOKEN", ""),
+		},
+		Fallback: FallbackConfig{
+			Window:          getEnvDuration("FALLBACK_WINDOW", 90*24*time.Hour),
+			HalfLife:        getEnvDuration("FALLBACK_HALF_LIFE", 14*24*time.Hour),
+			HistorySize:     getEnvInt("FALLBACK_HISTORY_SIZE", 20),
+			RefreshSchedule: getEnv("FALLBACK_REFRESH_SCHEDULE", "*/30 * * * *"),
 		},
 	}
 
@@ -73,6 +439,42 @@ func NewConfig() *Config {
 	if cfg.Gorse.APIKey == "" {
 		log.Printf("警告: GORSE_API_KEY 未设置，推荐功能可能无法正常工作")
 	}
+	if cfg.Gorse.RequestTimeout <= 0 || cfg.Gorse.FeedbackTimeout < 0 || cfg.Gorse.RecommendTimeout < 0 ||
+		cfg.Gorse.PopularTimeout < 0 || cfg.Gorse.NeighborsTimeout < 0 || cfg.Gorse.LatestTimeout < 0 {
+		log.Fatalf("Gorse配置无效: GORSE_REQUEST_TIMEOUT 必须大于0，各接口超时不能为负数")
+	}
+	if cfg.Gorse.MaxRetries < 0 || cfg.Gorse.RetryBackoff <= 0 || cfg.Gorse.BreakerThreshold < 0 || cfg.Gorse.BreakerCooldown <= 0 {
+		log.Fatalf("Gorse配置无效: GORSE_MAX_RETRIES、GORSE_BREAKER_THRESHOLD 不能为负数，GORSE_RETRY_BACKOFF、GORSE_BREAKER_COOLDOWN 必须大于0")
+	}
+	if cfg.Outbox.BatchSize <= 0 || cfg.Outbox.PollInterval <= 0 || cfg.Outbox.MaxAttempts <= 0 {
+		log.Fatalf("发件箱配置无效: OUTBOX_BATCH_SIZE、OUTBOX_POLL_INTERVAL、OUTBOX_MAX_ATTEMPTS 必须大于0")
+	}
+	if cfg.Recommend.OverfetchFactor <= 0 || cfg.Recommend.MaxFetchRounds <= 0 {
+		log.Fatalf("推荐配置无效: RECOMMEND_OVERFETCH_FACTOR、RECOMMEND_MAX_FETCH_ROUNDS 必须大于0")
+	}
+	if cfg.Admin.Token == "" {
+		log.Printf("警告: ADMIN_TOKEN 未设置，管理接口将拒绝所有请求")
+	}
+	if err := cfg.Recommend.Validate(); err != nil {
+		log.Fatalf("推荐配置无效: %v", err)
+	}
+	if cfg.Recommend.UsesLocal() {
+		if err := cfg.Fallback.Validate(); err != nil {
+			log.Fatalf("本地推荐配置无效: %v", err)
+		}
+	}
+	if err := cfg.Catalog.Validate(); err != nil {
+		log.Fatalf("馆藏数据平台配置无效: %v", err)
+	}
+	if !cfg.Catalog.Enabled() {
+		log.Printf("警告: CATALOG_APP_ID 未设置，馆藏同步任务不会启动")
+	}
+	if err := cfg.Circulation.Validate(); err != nil {
+		log.Fatalf("流通记录导入配置无效: %v", err)
+	}
+	if cfg.Circulation.Enabled() && !cfg.Catalog.Enabled() {
+		log.Printf("警告: CIRCULATION_PATH 已设置但 CATALOG_APP_ID 未设置，流通记录定时导入不会启动")
+	}
 
 	return cfg
 }
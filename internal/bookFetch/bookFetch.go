@@ -1,155 +1,150 @@
 package bookFetch
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"library/config"
+	"library/internal/dataplatform"
+	"library/internal/model"
+	"library/internal/repository"
+	"log"
 	"time"
 
 	"gorm.io/gorm"
 )
 
-// BookInfo 图书信息结构体
-type BookInfo struct {
-	ID                   string    `json:"id" gorm:"primaryKey"`
-	BookID               string    `json:"tsbh"`  // 图书编号
-	BookBarcode          string    `json:"tstxm"` // 图书条形码
-	Title                string    `json:"zbt"`   // 正标题
-	PublicationNumber    string    `json:"tscbh"` // 图书出版号
-	PrimaryAuthor        string    `json:"dyzz"`  // 第一作者
-	ClassificationNumber string    `json:"flh"`   // 分类号
-	LanguageCode         string    `json:"yzm"`   // 语种码
-	Edition              string    `json:"bc"`    // 版次
-	Publisher            string    `json:"cbs"`   // 出版社
-	PublicationPlace     string    `json:"cbd"`   // 出版地
-	PublicationDate      string    `json:"cbrq"`  // 出版日期
-	DistributionUnit     string    `json:"fxdw"`  // 发行单位
-	Notes                string    `json:"bz"`    // 备注
-	CreatedAt            time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt            time.Time `json:"updated_at" gorm:"autoUpdateTime"`
-}
-
-type getTokenReq struct {
-	AppId string `json:"appId"`
-	Code  int    `json:"code"`
-}
-
-// TokenResponse token API 响应结构
-type TokenResponse struct {
-	Code    int  `json:"code"`
-	Success bool `json:"success"`
-	Data    struct {
-		Expire int    `json:"expire"`
-		Token  string `json:"token"`
-	} `json:"data"`
-	Msg string `json:"msg"`
-}
-
 type getBookeReq struct {
 	PageNum  int `json:"pageNum"`
 	PageSize int `json:"pageSize"`
 }
 
-func getToken() (string, error) {
-	apiURL := "https://222.204.7.196:33027/api/token/getToken"
-
-	req := getTokenReq{
-		AppId: "1494732411679d",
-		Code:  914,
-	}
+// Fetcher 上游馆藏数据平台同步器
+type Fetcher struct {
+	db       *gorm.DB
+	bookRepo repository.BookRepository
+	platform *dataplatform.Client
+	cfg      config.CatalogConfig
+}
 
-	jsonReq, err := json.Marshal(req)
-	if err != nil {
-		return fmt.Sprintln("序列化请求数据失败"), err
-	}
+// NewFetcher 创建同步器
+func NewFetcher(db *gorm.DB, bookRepo repository.BookRepository, platform *dataplatform.Client, cfg config.CatalogConfig) *Fetcher {
+	return &Fetcher{db: db, bookRepo: bookRepo, platform: platform, cfg: cfg}
+}
 
-	resp, err := http.Post(apiURL, "application/json", bytes.NewBuffer(jsonReq))
-	if err != nil {
-		return fmt.Sprintln("获取token失败"), err
-	}
-	defer resp.Body.Close().Error()
+// Sync 从API逐页获取全部图书数据并按图书编号幂等写入数据库，返回本次同步的统计结果
+// 每完成一页记录断点，中途失败、ctx 取消或重启后从断点的下一页继续；
+// 从第一页完整遍历后，本轮未出现的记录视为上游已删除
+func (f *Fetcher) Sync(ctx context.Context) (*SyncStats, error) {
+	stats := &SyncStats{StartedAt: time.Now()}
 
-	body, err := io.ReadAll(resp.Body)
+	checkpoint, err := f.loadCheckpoint()
 	if err != nil {
-		return fmt.Sprintln("读取响应内容失败 "), err
+		return stats, err
 	}
-
-	var tokenResp TokenResponse
-	if err := json.Unmarshal(body, &tokenResp); err != nil {
-		return fmt.Sprintln("解析响应内容失败"), err
-	}
-
-	if tokenResp.Code != 200 {
-		return fmt.Sprintln("获取token失败"), err
+	page := f.cfg.StartPage
+	detectRemovals := f.cfg.StartPage == 1
+	if !checkpoint.Finished && checkpoint.LastPage >= f.cfg.StartPage {
+		page = checkpoint.LastPage + 1
+		if checkpoint.StartedAt.IsZero() {
+			// 旧版本断点没有本轮开始时间，无法区分已同步的页，本轮不做删除检测
+			detectRemovals = false
+		} else {
+			stats.StartedAt = checkpoint.StartedAt
+		}
+		log.Printf("从断点继续同步馆藏数据，起始页: %d", page)
 	}
 
-	return tokenResp.Data.Token, nil
-	// 读取响应内容
-}
+	for {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
 
-// TableName 指定表名
+		records, err := f.fetchPageWithRetry(ctx, page)
+		if err != nil {
+			return stats, fmt.Errorf("获取第 %d 页失败: %v", page, err)
+		}
 
-// FetchAndSaveBooks 从API获取图书数据并保存到数据库
-func fetchAndSaveBooks(db *gorm.DB) error {
-	// API endpoint URL
-	apiURL := "https://222.204.7.196:33027/api/dwd_ryy_xszj_tsjbxxzlb"
+		if err := f.importPage(records, stats); err != nil {
+			return stats, fmt.Errorf("保存第 %d 页图书数据失败: %v", page, err)
+		}
+		stats.Pages++
 
-	//获取token
-	token, err := getToken()
-	if err != nil {
-		return fmt.Errorf("获取token失败: %v", err)
+		// 不足一页说明已到末尾
+		finished := len(records) < f.cfg.PageSize
+		if err := f.saveCheckpoint(page, finished, stats.StartedAt); err != nil {
+			return stats, err
+		}
+		if finished {
+			break
+		}
+		page++
 	}
 
-	reqBody := getBookeReq{
-		PageNum:  230,
-		PageSize: 1000,
-	}
-	jsonReq, err := json.Marshal(reqBody)
-	if err != nil {
-		return fmt.Errorf("序列化请求数据失败: %v", err)
+	if detectRemovals {
+		if err := f.removeMissing(stats); err != nil {
+			return stats, err
+		}
 	}
 
-	req, err := http.NewRequest(http.MethodPost, apiURL, bytes.NewBuffer(jsonReq))
-	if err != nil {
-		return fmt.Errorf("创建请求失败: %v", err)
-	}
+	log.Printf("馆藏同步完成，最后一页: %d，%s", page, stats)
+	return stats, nil
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-H3C-TOKEN", token)
+// fetchPageWithRetry 获取单页数据，失败时按指数退避重试
+func (f *Fetcher) fetchPageWithRetry(ctx context.Context, page int) ([]model.APIBookInfo, error) {
+	backoff := f.cfg.RetryBackoff
+	var lastErr error
+	for attempt := 0; attempt <= f.cfg.PageRetries; attempt++ {
+		if attempt > 0 {
+			log.Printf("第 %d 页获取失败，%s 后第 %d 次重试: %v", page, backoff, attempt, lastErr)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("发送请求失败: %v", err)
+		books, err := f.fetchPage(ctx, page)
+		if err == nil {
+			return books, nil
+		}
+		lastErr = err
 	}
+	return nil, lastErr
+}
 
-	// 读取响应内容
-	body, err := io.ReadAll(resp.Body)
+// fetchPage 获取单页图书数据
+func (f *Fetcher) fetchPage(ctx context.Context, page int) ([]model.APIBookInfo, error) {
+	body, err := f.platform.PostJSON(ctx, f.cfg.BooksPath, getBookeReq{
+		PageNum:  page,
+		PageSize: f.cfg.PageSize,
+	})
 	if err != nil {
-		return fmt.Errorf("读取响应内容失败: %v", err)
+		return nil, err
 	}
+	return decodeBooks(body)
+}
 
-	// 解析JSON数据到图书结构体切片
-	var books []BookInfo
-	if err := json.Unmarshal(body, &books); err != nil {
-		return fmt.Errorf("解析JSON数据失败: %v", err)
+// decodeBooks 解析图书数据，兼容直接返回数组和分页包装（records 位于顶层或 data 内）两种格式
+func decodeBooks(body []byte) ([]model.APIBookInfo, error) {
+	var books []model.APIBookInfo
+	if err := json.Unmarshal(body, &books); err == nil {
+		return books, nil
 	}
 
-	// 批量保存图书数据到数据库
-	if err := db.CreateInBatches(books, 1000).Error; err != nil {
-		return fmt.Errorf("批量保存图书数据失败: %v", err)
+	var wrapped struct {
+		Records []model.APIBookInfo `json:"records"`
+		Data    struct {
+			Records []model.APIBookInfo `json:"records"`
+		} `json:"data"`
 	}
-
-	fmt.Printf("成功保存 %d 本图书到数据库\n", len(books))
-	return nil
-}
-
-func TimelyFetchAndSaveBooks(db *gorm.DB) {
-	for {
-		if err := fetchAndSaveBooks(db); err != nil {
-			fmt.Println("定时任务执行失败:", err)
-		}
-		time.Sleep(24 * time.Hour) // 每小时执行一次
+	if err := json.Unmarshal(body, &wrapped); err != nil {
+		return nil, fmt.Errorf("解析JSON数据失败: %v", err)
+	}
+	if len(wrapped.Records) > 0 {
+		return wrapped.Records, nil
 	}
+	return wrapped.Data.Records, nil
 }
@@ -0,0 +1,45 @@
+package bookFetch
+
+import (
+	"errors"
+	"fmt"
+	"library/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// catalogCheckpointSource 馆藏图书同步的断点名称
+const catalogCheckpointSource = "catalog_books"
+
+// loadCheckpoint 读取馆藏同步断点，不存在时返回空断点
+func (f *Fetcher) loadCheckpoint() (*model.SyncCheckpoint, error) {
+	var checkpoint model.SyncCheckpoint
+	err := f.db.Where("source = ?", catalogCheckpointSource).First(&checkpoint).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &model.SyncCheckpoint{Source: catalogCheckpointSource}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取同步断点失败: %v", err)
+	}
+	return &checkpoint, nil
+}
+
+// saveCheckpoint 记录最后一个已完成的页码及本轮开始时间
+func (f *Fetcher) saveCheckpoint(page int, finished bool, startedAt time.Time) error {
+	checkpoint := model.SyncCheckpoint{
+		Source:    catalogCheckpointSource,
+		LastPage:  page,
+		Finished:  finished,
+		StartedAt: startedAt,
+	}
+	err := f.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "source"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_page", "finished", "started_at", "updated_at"}),
+	}).Create(&checkpoint).Error
+	if err != nil {
+		return fmt.Errorf("保存同步断点失败: %v", err)
+	}
+	return nil
+}
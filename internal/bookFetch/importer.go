@@ -0,0 +1,166 @@
+package bookFetch
+
+import (
+	"fmt"
+	"library/config"
+	"library/internal/model"
+	"log"
+	"time"
+)
+
+// SyncStats 单次同步的统计结果
+type SyncStats struct {
+	StartedAt time.Time `json:"started_at"` // 本轮开始时间，断点续传时沿用首次开始时间
+	Pages     int       `json:"pages"`      // 处理的页数
+	Fetched   int       `json:"fetched"`    // 从数据平台获取的记录数
+	Inserted  int       `json:"inserted"`   // 新增的记录数
+	Updated   int       `json:"updated"`    // 编目字段有变化而更新的记录数
+	Unchanged int       `json:"unchanged"`  // 未变化的记录数
+	Skipped   int       `json:"skipped"`    // 无法解析或条形码冲突而跳过的记录数
+	Removed   int       `json:"removed"`    // 上游已删除而标记丢失或软删除的记录数
+}
+
+// String 返回统计结果的可读表示
+func (s SyncStats) String() string {
+	return fmt.Sprintf("页数 %d，获取 %d，新增 %d，更新 %d，未变化 %d，跳过 %d，删除 %d",
+		s.Pages, s.Fetched, s.Inserted, s.Updated, s.Unchanged, s.Skipped, s.Removed)
+}
+
+// importPage 将一页数据平台记录转换为馆藏模型并按图书编号幂等写入，统计结果累加到 stats
+// 编目摘要未变化的记录只更新同步时间，曾因上游删除被标记为丢失的记录重新出现时恢复为可借并重新发布；条形码为空、已被其他图书编号占用或与同页前面的记录重复时跳过，避免违反唯一约束，已入库的跳过记录仍更新同步时间
+func (f *Fetcher) importPage(records []model.APIBookInfo, stats *SyncStats) error {
+	stats.Fetched += len(records)
+
+	// 同页内同一图书编号以最后一条为准，ON CONFLICT 不允许同一语句重复更新同一行
+	byBookID := make(map[string]*model.BookInfo, len(records))
+	order := make([]string, 0, len(records))
+	for i := range records {
+		book, err := records[i].ToBookInfo()
+		if err != nil || book.BookID == "" {
+			log.Printf("跳过无法解析的馆藏记录 id=%q tsbh=%q: %v", records[i].ID, records[i].BookID, err)
+			stats.Skipped++
+			continue
+		}
+		book.ContentHash = book.CatalogHash()
+		book.SyncedAt = &stats.StartedAt
+		if _, ok := byBookID[book.BookID]; ok {
+			stats.Skipped++
+		} else {
+			order = append(order, book.BookID)
+		}
+		byBookID[book.BookID] = book
+	}
+	if len(order) == 0 {
+		return nil
+	}
+
+	existing, err := f.bookRepo.FindByBookIDs(order)
+	if err != nil {
+		return fmt.Errorf("查询已有馆藏失败: %v", err)
+	}
+	existingByID := make(map[string]*model.BookInfo, len(existing))
+	for _, book := range existing {
+		existingByID[book.BookID] = book
+	}
+
+	barcodes := make([]string, 0, len(order))
+	for _, bookID := range order {
+		if barcode := byBookID[bookID].BookBarcode; barcode != "" {
+			barcodes = append(barcodes, barcode)
+		}
+	}
+	barcodeOwners, err := f.bookRepo.FindByBarcodes(barcodes)
+	if err != nil {
+		return fmt.Errorf("查询条形码失败: %v", err)
+	}
+	ownerByBarcode := make(map[string]string, len(barcodeOwners))
+	for _, book := range barcodeOwners {
+		ownerByBarcode[book.BookBarcode] = book.BookID
+	}
+
+	upserts := make([]*model.BookInfo, 0, len(order))
+	unchanged := make([]string, 0, len(order))
+	var restores []string
+	var touched []string                           // 跳过但已入库的记录，与未变化的记录一起更新同步时间
+	claimed := make(map[string]string, len(order)) // 条形码 -> 本页中先出现的图书编号
+	inserted, updated := 0, 0
+	for _, bookID := range order {
+		book := byBookID[bookID]
+		current, exists := existingByID[bookID]
+		skip := ""
+		if book.BookBarcode == "" {
+			skip = "条形码为空"
+		} else if owner, ok := ownerByBarcode[book.BookBarcode]; ok && owner != book.BookID {
+			skip = fmt.Sprintf("条形码 %s 已属于图书 %s", book.BookBarcode, owner)
+		} else if owner, ok := claimed[book.BookBarcode]; ok {
+			skip = fmt.Sprintf("条形码 %s 与同页图书 %s 重复", book.BookBarcode, owner)
+		}
+		if skip != "" {
+			log.Printf("跳过馆藏记录 %s：%s", book.BookID, skip)
+			stats.Skipped++
+			if exists {
+				// 记录仍在上游，只是本次无法写入，保留已有数据并更新同步时间，避免被当作上游已删除
+				touched = append(touched, bookID)
+			}
+			continue
+		}
+		claimed[book.BookBarcode] = book.BookID
+
+		switch {
+		case !exists:
+			inserted++
+		case current.RemovedBySync && current.Status == model.BookStatusLost:
+			// 曾被同步标记为丢失的图书重新出现，视为变化并恢复为可借
+			restores = append(restores, bookID)
+			updated++
+		case current.ContentHash == book.ContentHash:
+			unchanged = append(unchanged, bookID)
+			continue
+		default:
+			updated++
+		}
+		upserts = append(upserts, book)
+	}
+
+	if err := f.bookRepo.UpsertBooks(upserts); err != nil {
+		return fmt.Errorf("写入馆藏数据失败: %v", err)
+	}
+	if _, err := f.bookRepo.RestoreRemovedBySync(restores); err != nil {
+		return fmt.Errorf("恢复重新出现的馆藏失败: %v", err)
+	}
+	if err := f.bookRepo.TouchSynced(append(touched, unchanged...), stats.StartedAt); err != nil {
+		return fmt.Errorf("更新同步时间失败: %v", err)
+	}
+	stats.Inserted += inserted
+	stats.Updated += updated
+	stats.Unchanged += len(unchanged)
+	return nil
+}
+
+// removeMissing 处理本轮未在上游出现的记录，按配置标记为丢失或软删除
+// 待处理记录占比超过 MaxRemovalRatio 时视为上游数据异常（如返回空页），跳过本轮删除
+func (f *Fetcher) removeMissing(stats *SyncStats) error {
+	softDelete := f.cfg.RemovalMode == config.CatalogRemovalDelete
+	missing, err := f.bookRepo.CountNotSyncedSince(stats.StartedAt, softDelete)
+	if err != nil {
+		return fmt.Errorf("统计上游已删除记录失败: %v", err)
+	}
+	if missing == 0 {
+		return nil
+	}
+
+	total := missing + int64(stats.Fetched)
+	if float64(missing) > f.cfg.MaxRemovalRatio*float64(total) {
+		log.Printf("上游已删除 %d 条记录，超过允许占比 %.0f%%（本轮获取 %d 条），跳过删除处理",
+			missing, f.cfg.MaxRemovalRatio*100, stats.Fetched)
+		return nil
+	}
+
+	removed, err := f.bookRepo.RemoveNotSyncedSince(stats.StartedAt, softDelete)
+	if err != nil {
+		return fmt.Errorf("处理上游已删除记录失败: %v", err)
+	}
+	stats.Removed = len(removed)
+	log.Printf("上游已删除 %d 条馆藏记录，处理方式: %s", len(removed), f.cfg.RemovalMode)
+	return nil
+}
@@ -0,0 +1,95 @@
+package circulation
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"library/internal/model"
+	"strings"
+)
+
+// csvBatchSize CSV导入时每批写入的记录数
+const csvBatchSize = 1000
+
+// csvColumns CSV表头到字段的映射，同时接受英文列名和数据平台字段名
+var csvColumns = map[string]func(r *model.APICirculationRecord, v string){
+	"id":           func(r *model.APICirculationRecord, v string) { r.ID = v },
+	"user_id":      func(r *model.APICirculationRecord, v string) { r.UserID = v },
+	"dzzh":         func(r *model.APICirculationRecord, v string) { r.UserID = v },
+	"book_id":      func(r *model.APICirculationRecord, v string) { r.BookID = v },
+	"tsbh":         func(r *model.APICirculationRecord, v string) { r.BookID = v },
+	"book_barcode": func(r *model.APICirculationRecord, v string) { r.BookBarcode = v },
+	"tstxm":        func(r *model.APICirculationRecord, v string) { r.BookBarcode = v },
+	"loan_time":    func(r *model.APICirculationRecord, v string) { r.LoanTime = v },
+	"jcsj":         func(r *model.APICirculationRecord, v string) { r.LoanTime = v },
+	"due_time":     func(r *model.APICirculationRecord, v string) { r.DueTime = v },
+	"yhsj":         func(r *model.APICirculationRecord, v string) { r.DueTime = v },
+	"return_time":  func(r *model.APICirculationRecord, v string) { r.ReturnTime = v },
+	"ghsj":         func(r *model.APICirculationRecord, v string) { r.ReturnTime = v },
+}
+
+// ImportCSV 导入流通系统导出的CSV文件，首行为表头，未识别的列忽略
+// 必需列：user_id(dzzh)、loan_time(jcsj)，以及 book_id(tsbh) 或 book_barcode(tstxm) 之一
+func (im *Importer) ImportCSV(r io.Reader) (*ImportStats, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("读取CSV表头失败: %v", err)
+	}
+	setters := make([]func(*model.APICirculationRecord, string), len(header))
+	found := make(map[string]bool)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if setter, ok := csvColumns[name]; ok {
+			setters[i] = setter
+			found[name] = true
+		}
+	}
+	if !(found["user_id"] || found["dzzh"]) || !(found["loan_time"] || found["jcsj"]) ||
+		!(found["book_id"] || found["tsbh"] || found["book_barcode"] || found["tstxm"]) {
+		return nil, fmt.Errorf("CSV缺少必需列，表头为: %v", header)
+	}
+
+	stats := &ImportStats{}
+	batch := make([]model.APICirculationRecord, 0, csvBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := im.Import(batch, stats)
+		batch = batch[:0]
+		return err
+	}
+
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return stats, fmt.Errorf("读取CSV第 %d 行失败: %v", line, err)
+		}
+
+		var record model.APICirculationRecord
+		for i, value := range row {
+			if i < len(setters) && setters[i] != nil {
+				setters[i](&record, strings.TrimSpace(value))
+			}
+		}
+		batch = append(batch, record)
+
+		if len(batch) >= csvBatchSize {
+			if err := flush(); err != nil {
+				return stats, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return stats, err
+	}
+	return stats, nil
+}
@@ -0,0 +1,295 @@
+// Package circulation 导入图书流通（借还）记录，作为借阅反馈写入Gorse并同步图书借阅状态
+package circulation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"library/config"
+	"library/internal/dataplatform"
+	"library/internal/model"
+	"library/internal/repository"
+	"log"
+	"time"
+)
+
+// ImportStats 单次导入的统计结果
+type ImportStats struct {
+	Fetched       int `json:"fetched"`        // 读取的记录数
+	Inserted      int `json:"inserted"`       // 新增的记录数（同时写入借阅反馈）
+	Updated       int `json:"updated"`        // 已存在而更新归还时间的记录数
+	Skipped       int `json:"skipped"`        // 无法解析或匹配不到馆藏而跳过的记录数
+	StatusChanged int `json:"status_changed"` // 借阅状态有变化的图书数
+}
+
+// String 返回统计结果的可读表示
+func (s ImportStats) String() string {
+	return fmt.Sprintf("读取 %d，新增 %d，更新 %d，跳过 %d，状态变化 %d",
+		s.Fetched, s.Inserted, s.Updated, s.Skipped, s.StatusChanged)
+}
+
+// getRecordsReq 流通记录分页请求，StartTime 为空时拉取全部历史
+type getRecordsReq struct {
+	PageNum   int    `json:"pageNum"`
+	PageSize  int    `json:"pageSize"`
+	StartTime string `json:"startTime,omitempty"` // 只返回该时间之后借出或归还的记录
+}
+
+// requestTimeLayout 请求中时间参数的格式
+const requestTimeLayout = "2006-01-02 15:04:05"
+
+// Importer 流通记录导入器
+type Importer struct {
+	circulationRepo repository.CirculationRepository
+	bookRepo        repository.BookRepository
+	platform        *dataplatform.Client // 为 nil 时只能导入CSV
+	cfg             config.CirculationConfig
+	notify          func() // 有新的借阅反馈写入发件箱后调用（可为nil）
+}
+
+// NewImporter 创建导入器
+func NewImporter(circulationRepo repository.CirculationRepository, bookRepo repository.BookRepository, platform *dataplatform.Client, cfg config.CirculationConfig, notify func()) *Importer {
+	return &Importer{
+		circulationRepo: circulationRepo,
+		bookRepo:        bookRepo,
+		platform:        platform,
+		cfg:             cfg,
+		notify:          notify,
+	}
+}
+
+// Sync 从数据平台逐页拉取流通记录并导入，记录按ID幂等写入，可重复执行
+// 首次同步拉取全部历史，之后只拉取上一次完整同步开始时间（减去 SyncOverlap）之后变化的记录；
+// 中途失败时不推进起点，下次从同一起点重新拉取
+func (im *Importer) Sync(ctx context.Context) (*ImportStats, error) {
+	if im.platform == nil || im.cfg.Path == "" {
+		return nil, fmt.Errorf("未配置流通记录接口")
+	}
+
+	startedAt := time.Now()
+	lastSyncedAt, err := im.circulationRepo.LastSyncedAt()
+	if err != nil {
+		return nil, fmt.Errorf("读取流通记录同步断点失败: %v", err)
+	}
+	var since *time.Time
+	if lastSyncedAt != nil {
+		t := lastSyncedAt.Add(-im.cfg.SyncOverlap)
+		since = &t
+	}
+
+	stats := &ImportStats{}
+	for page := 1; ; page++ {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		records, err := im.fetchPageWithRetry(ctx, page, since)
+		if err != nil {
+			return stats, fmt.Errorf("获取第 %d 页流通记录失败: %v", page, err)
+		}
+		if err := im.Import(records, stats); err != nil {
+			return stats, fmt.Errorf("导入第 %d 页流通记录失败: %v", page, err)
+		}
+		if len(records) < im.cfg.PageSize {
+			break
+		}
+	}
+
+	if err := im.circulationRepo.SaveSyncedAt(startedAt); err != nil {
+		return stats, fmt.Errorf("保存流通记录同步断点失败: %v", err)
+	}
+	log.Printf("流通记录同步完成，%s", stats)
+	return stats, nil
+}
+
+// fetchPageWithRetry 获取单页数据，失败时按指数退避重试
+func (im *Importer) fetchPageWithRetry(ctx context.Context, page int, since *time.Time) ([]model.APICirculationRecord, error) {
+	backoff := im.cfg.RetryBackoff
+	var lastErr error
+	for attempt := 0; attempt <= im.cfg.PageRetries; attempt++ {
+		if attempt > 0 {
+			log.Printf("流通记录第 %d 页获取失败，%s 后第 %d 次重试: %v", page, backoff, attempt, lastErr)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		records, err := im.fetchPage(ctx, page, since)
+		if err == nil {
+			return records, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// fetchPage 获取单页流通记录，since 不为空时只获取其后变化的记录
+func (im *Importer) fetchPage(ctx context.Context, page int, since *time.Time) ([]model.APICirculationRecord, error) {
+	req := getRecordsReq{
+		PageNum:  page,
+		PageSize: im.cfg.PageSize,
+	}
+	if since != nil {
+		req.StartTime = since.Format(requestTimeLayout)
+	}
+	body, err := im.platform.PostJSON(ctx, im.cfg.Path, req)
+	if err != nil {
+		return nil, err
+	}
+	return decodeRecords(body)
+}
+
+// decodeRecords 解析流通记录，兼容直接返回数组和分页包装（records 位于顶层或 data 内）两种格式
+func decodeRecords(body []byte) ([]model.APICirculationRecord, error) {
+	var records []model.APICirculationRecord
+	if err := json.Unmarshal(body, &records); err == nil {
+		return records, nil
+	}
+
+	var wrapped struct {
+		Records []model.APICirculationRecord `json:"records"`
+		Data    struct {
+			Records []model.APICirculationRecord `json:"records"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &wrapped); err != nil {
+		return nil, fmt.Errorf("解析JSON数据失败: %v", err)
+	}
+	if len(wrapped.Records) > 0 {
+		return wrapped.Records, nil
+	}
+	return wrapped.Data.Records, nil
+}
+
+// Import 导入一批流通记录，统计结果累加到 stats
+// 只有条形码的记录按条形码匹配图书编号，匹配不到馆藏的记录跳过；新记录在同一事务中写入借阅反馈，
+// 之后按每本书最近一次借出记录刷新借阅状态
+func (im *Importer) Import(raw []model.APICirculationRecord, stats *ImportStats) error {
+	stats.Fetched += len(raw)
+
+	byID := make(map[string]*model.CirculationRecord, len(raw))
+	order := make([]string, 0, len(raw))
+	for i := range raw {
+		record, err := raw[i].ToCirculationRecord()
+		if err != nil {
+			log.Printf("跳过无法解析的流通记录 id=%q: %v", raw[i].ID, err)
+			stats.Skipped++
+			continue
+		}
+		if _, ok := byID[record.ID]; ok {
+			stats.Skipped++
+		} else {
+			order = append(order, record.ID)
+		}
+		byID[record.ID] = record
+	}
+	if len(order) == 0 {
+		return nil
+	}
+
+	records := make([]*model.CirculationRecord, 0, len(order))
+	for _, id := range order {
+		records = append(records, byID[id])
+	}
+	records, err := im.resolveBooks(records, stats)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(records))
+	for _, record := range records {
+		ids = append(ids, record.ID)
+	}
+	existingIDs, err := im.circulationRepo.FindExistingIDs(ids)
+	if err != nil {
+		return fmt.Errorf("查询已有流通记录失败: %v", err)
+	}
+	existing := make(map[string]bool, len(existingIDs))
+	for _, id := range existingIDs {
+		existing[id] = true
+	}
+
+	feedback := make([]*model.FeedbackOutbox, 0, len(records))
+	bookIDs := make([]string, 0, len(records))
+	seenBooks := make(map[string]bool, len(records))
+	for _, record := range records {
+		if !existing[record.ID] {
+			feedback = append(feedback, model.NewBorrowOutbox(record))
+		}
+		if !seenBooks[record.BookID] {
+			seenBooks[record.BookID] = true
+			bookIDs = append(bookIDs, record.BookID)
+		}
+	}
+
+	if err := im.circulationRepo.SaveWithOutbox(records, feedback); err != nil {
+		return fmt.Errorf("保存流通记录失败: %v", err)
+	}
+	stats.Inserted += len(feedback)
+	stats.Updated += len(records) - len(feedback)
+
+	changed, err := im.circulationRepo.RefreshBookStatuses(bookIDs)
+	if err != nil {
+		return fmt.Errorf("更新图书借阅状态失败: %v", err)
+	}
+	stats.StatusChanged += int(changed)
+
+	if len(feedback) > 0 && im.notify != nil {
+		im.notify()
+	}
+	return nil
+}
+
+// resolveBooks 补全图书编号并剔除匹配不到馆藏的记录
+func (im *Importer) resolveBooks(records []*model.CirculationRecord, stats *ImportStats) ([]*model.CirculationRecord, error) {
+	var bookIDs, barcodes []string
+	for _, record := range records {
+		if record.BookID != "" {
+			bookIDs = append(bookIDs, record.BookID)
+		} else {
+			barcodes = append(barcodes, record.BookBarcode)
+		}
+	}
+
+	known := make(map[string]bool, len(bookIDs))
+	if len(bookIDs) > 0 {
+		books, err := im.bookRepo.FindByBookIDs(bookIDs)
+		if err != nil {
+			return nil, fmt.Errorf("查询馆藏失败: %v", err)
+		}
+		for _, book := range books {
+			known[book.BookID] = true
+		}
+	}
+	byBarcode := make(map[string]string, len(barcodes))
+	if len(barcodes) > 0 {
+		books, err := im.bookRepo.FindByBarcodes(barcodes)
+		if err != nil {
+			return nil, fmt.Errorf("按条形码查询馆藏失败: %v", err)
+		}
+		for _, book := range books {
+			byBarcode[book.BookBarcode] = book.BookID
+			known[book.BookID] = true
+		}
+	}
+
+	resolved := records[:0]
+	for _, record := range records {
+		if record.BookID == "" {
+			record.BookID = byBarcode[record.BookBarcode]
+		}
+		if record.BookID == "" || !known[record.BookID] {
+			log.Printf("跳过流通记录 %s：馆藏中没有图书 %q（条形码 %q）", record.ID, record.BookID, record.BookBarcode)
+			stats.Skipped++
+			continue
+		}
+		resolved = append(resolved, record)
+	}
+	return resolved, nil
+}
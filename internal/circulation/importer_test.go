@@ -0,0 +1,54 @@
+package circulation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeRecords(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"直接返回数组", `[{"id":"1","dzzh":"u1"},{"id":"2","dzzh":"u2"}]`, []string{"1", "2"}},
+		{"顶层records", `{"records":[{"id":"1"}]}`, []string{"1"}},
+		{"data内records", `{"code":200,"data":{"records":[{"id":"1"},{"id":"2"}]}}`, []string{"1", "2"}},
+		{"空页", `{"data":{"records":[]}}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := decodeRecords([]byte(tt.body))
+			if err != nil {
+				t.Fatalf("decodeRecords 返回错误: %v", err)
+			}
+			if len(records) != len(tt.want) {
+				t.Fatalf("解析出 %d 条记录，期望 %d 条", len(records), len(tt.want))
+			}
+			for i, id := range tt.want {
+				if records[i].ID != id {
+					t.Errorf("第 %d 条记录ID = %q，期望 %q", i, records[i].ID, id)
+				}
+			}
+		})
+	}
+
+	if _, err := decodeRecords([]byte(`not json`)); err == nil {
+		t.Errorf("无效JSON期望返回错误")
+	}
+}
+
+func TestImportCSVRequiredColumns(t *testing.T) {
+	headers := []string{
+		"book_id,loan_time",
+		"user_id,book_id",
+		"user_id,loan_time",
+		"",
+	}
+	for _, header := range headers {
+		if _, err := (&Importer{}).ImportCSV(strings.NewReader(header + "\n")); err == nil {
+			t.Errorf("表头 %q 缺少必需列，期望返回错误", header)
+		}
+	}
+}
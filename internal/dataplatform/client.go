@@ -0,0 +1,142 @@
+// Package dataplatform 校园数据平台客户端，统一处理 X-H3C-TOKEN 鉴权、token 缓存和错误解析
+package dataplatform
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"library/config"
+	"net/http"
+	"os"
+)
+
+// APIError 数据平台返回的错误，保留HTTP状态码和平台的 code、msg 便于排查
+type APIError struct {
+	StatusCode int    // HTTP状态码
+	Code       int    // 平台响应中的 code，未返回时为0
+	Msg        string // 平台响应中的 msg，无法解析时为原始响应
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("数据平台返回错误 (HTTP %d, code %d): %s", e.StatusCode, e.Code, e.Msg)
+}
+
+// IsUnauthorized 判断是否为 token 失效
+func (e *APIError) IsUnauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.Code == http.StatusUnauthorized
+}
+
+// Client 数据平台客户端
+type Client struct {
+	baseURL string
+	http    *http.Client
+	tokens  *TokenProvider
+}
+
+// NewClient 根据配置创建客户端，加载自定义CA证书失败时返回错误
+func NewClient(cfg config.CatalogConfig) (*Client, error) {
+	httpClient, err := newHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		baseURL: cfg.BaseURL,
+		http:    httpClient,
+		tokens:  NewTokenProvider(httpClient, cfg.BaseURL+cfg.TokenPath, cfg.AppID, cfg.AppCode, cfg.TokenRefreshMargin),
+	}, nil
+}
+
+// newHTTPClient 按配置构建带超时和TLS选项的HTTP客户端
+func newHTTPClient(cfg config.CatalogConfig) (*http.Client, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取CA证书失败: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA证书文件中没有有效的PEM证书: %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{
+		Timeout:   cfg.RequestTimeout,
+		Transport: transport,
+	}, nil
+}
+
+// PostJSON 携带 X-H3C-TOKEN 向 path 发送JSON请求并返回响应内容
+// token 失效（HTTP 401 或响应 code 为 401）时刷新 token 并重试一次
+func (c *Client) PostJSON(ctx context.Context, path string, payload interface{}) ([]byte, error) {
+	jsonReq, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求数据失败: %v", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		token, err := c.tokens.Token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("获取token失败: %w", err)
+		}
+
+		body, err := c.post(ctx, c.baseURL+path, token, jsonReq)
+		var apiErr *APIError
+		if attempt == 0 && errors.As(err, &apiErr) && apiErr.IsUnauthorized() {
+			c.tokens.Invalidate(token)
+			continue
+		}
+		return body, err
+	}
+}
+
+// post 发送单次请求，非200状态或响应 code 表示失败时返回 *APIError
+func (c *Client) post(ctx context.Context, apiURL, token string, jsonReq []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(jsonReq))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-H3C-TOKEN", token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("发送请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应内容失败: %v", err)
+	}
+
+	// 数据平台出错时可能仍返回HTTP 200，以响应中的 code 为准
+	var envelope struct {
+		Code *int   `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	_ = json.Unmarshal(body, &envelope)
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Msg: envelope.Msg}
+		if envelope.Code != nil {
+			apiErr.Code = *envelope.Code
+		}
+		if apiErr.Msg == "" {
+			apiErr.Msg = string(body)
+		}
+		return nil, apiErr
+	}
+	if envelope.Code != nil && *envelope.Code != http.StatusOK && *envelope.Code != 0 {
+		return nil, &APIError{StatusCode: resp.StatusCode, Code: *envelope.Code, Msg: envelope.Msg}
+	}
+	return body, nil
+}
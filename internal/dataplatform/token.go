@@ -0,0 +1,134 @@
+package dataplatform
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type getTokenReq struct {
+	AppId string `json:"appId"`
+	Code  int    `json:"code"`
+}
+
+// TokenResponse token API 响应结构
+type TokenResponse struct {
+	Code    int  `json:"code"`
+	Success bool `json:"success"`
+	Data    struct {
+		Expire int64  `json:"expire"`
+		Token  string `json:"token"`
+	} `json:"data"`
+	Msg string `json:"msg"`
+}
+
+// TokenProvider 数据平台 token 提供者
+// 缓存 token 直到过期前 refreshMargin，接口返回 401 时调用 Invalidate 强制下次重新获取；并发安全
+type TokenProvider struct {
+	client        *http.Client
+	tokenURL      string
+	appID         string
+	code          int
+	refreshMargin time.Duration
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time // 零值表示平台未返回有效期，缓存到失效为止
+}
+
+// NewTokenProvider 创建 token 提供者
+func NewTokenProvider(client *http.Client, tokenURL, appID string, code int, refreshMargin time.Duration) *TokenProvider {
+	return &TokenProvider{
+		client:        client,
+		tokenURL:      tokenURL,
+		appID:         appID,
+		code:          code,
+		refreshMargin: refreshMargin,
+	}
+}
+
+// Token 返回有效的 token，缓存为空或即将过期时重新获取
+func (p *TokenProvider) Token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && (p.expiresAt.IsZero() || time.Now().Add(p.refreshMargin).Before(p.expiresAt)) {
+		return p.token, nil
+	}
+
+	token, expiresAt, err := p.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	p.token = token
+	p.expiresAt = expiresAt
+	return token, nil
+}
+
+// Invalidate 丢弃缓存的 token；token 已被其他请求刷新时不做处理
+func (p *TokenProvider) Invalidate(token string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.token == token {
+		p.token = ""
+		p.expiresAt = time.Time{}
+	}
+}
+
+// fetch 向数据平台申请新 token
+func (p *TokenProvider) fetch(ctx context.Context) (string, time.Time, error) {
+	jsonReq, err := json.Marshal(getTokenReq{AppId: p.appID, Code: p.code})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("序列化请求数据失败: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.tokenURL, bytes.NewBuffer(jsonReq))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("获取token失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("读取响应内容失败: %v", err)
+	}
+
+	var tokenResp TokenResponse
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return "", time.Time{}, &APIError{StatusCode: resp.StatusCode, Msg: string(body)}
+		}
+		return "", time.Time{}, fmt.Errorf("解析响应内容失败: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || tokenResp.Code != http.StatusOK || tokenResp.Data.Token == "" {
+		return "", time.Time{}, &APIError{StatusCode: resp.StatusCode, Code: tokenResp.Code, Msg: tokenResp.Msg}
+	}
+
+	return tokenResp.Data.Token, expiryTime(tokenResp.Data.Expire, time.Now()), nil
+}
+
+// expiryTime 将平台返回的 expire 换算为过期时间
+// 数值较大时视为Unix时间戳（秒或毫秒），否则视为有效秒数；非正数表示未知
+func expiryTime(expire int64, now time.Time) time.Time {
+	switch {
+	case expire <= 0:
+		return time.Time{}
+	case expire > 1e12:
+		return time.UnixMilli(expire)
+	case expire > 1e9:
+		return time.Unix(expire, 0)
+	default:
+		return now.Add(time.Duration(expire) * time.Second)
+	}
+}
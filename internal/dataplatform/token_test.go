@@ -0,0 +1,30 @@
+package dataplatform
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpiryTime(t *testing.T) {
+	now := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		expire int64
+		want   time.Time
+	}{
+		{"零表示未知", 0, time.Time{}},
+		{"负数表示未知", -1, time.Time{}},
+		{"有效秒数", 7200, now.Add(2 * time.Hour)},
+		{"秒级时间戳", 1792231200, time.Unix(1792231200, 0)},
+		{"毫秒级时间戳", 1792231200123, time.UnixMilli(1792231200123)},
+		{"秒数与时间戳的分界", 1e9, now.Add(1e9 * time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expiryTime(tt.expire, now); !got.Equal(tt.want) {
+				t.Errorf("expiryTime(%d) = %s，期望 %s", tt.expire, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,366 @@
+// Package fallback 根据本地 user_behaviors 计算推荐，在 Gorse 不可用时作为降级方案
+// 模型定期整体重建并保存在内存中：交互包括 user_behaviors 中的行为和 circulation_records 中的借阅，热门度按交互权重随时间衰减累加，相似图书为共同读者上的余弦相似度，
+// 个性化推荐以读者最近交互的图书为种子做 item-kNN
+package fallback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"library/config"
+	"library/internal/gorse"
+	"library/internal/model"
+	"library/internal/repository"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// loadPageSize 加载行为记录的分页大小
+	loadPageSize = 5000
+	// categoryBatchSize 查询图书分类的批大小
+	categoryBatchSize = 1000
+)
+
+// ErrNotReady 模型尚未完成首次计算
+var ErrNotReady = errors.New("本地推荐模型尚未就绪")
+
+// feedbackWeights 各反馈类型的交互权重，未列出的类型权重为1
+var feedbackWeights = map[string]float64{
+	"view":                   1,
+	"click":                  2,
+	"read":                   4,
+	model.FeedbackTypeBorrow: 5,
+}
+
+// Engine 本地降级推荐引擎，可并发使用
+type Engine struct {
+	behaviorRepo    repository.BehaviorRepository
+	circulationRepo repository.CirculationRepository
+	bookRepo        repository.BookRepository
+	cfg             config.FallbackConfig
+
+	refreshMu sync.Mutex // 保证同一时间只有一次重建
+	mu        sync.RWMutex
+	snap      *snapshot
+}
+
+// snapshot 某一时刻计算出的推荐模型，创建后只读
+type snapshot struct {
+	builtAt    time.Time
+	popular    []gorse.Score                 // 按衰减热度降序
+	itemUsers  map[string]map[string]float64 // 图书 -> 读者 -> 衰减后的交互权重
+	userItems  map[string]map[string]float64 // 读者 -> 图书 -> 衰减后的交互权重
+	userRecent map[string][]string           // 读者 -> 最近交互的图书（时间倒序，最多 HistorySize 本）
+	itemNorms  map[string]float64            // 图书交互向量的模长，用于余弦相似度
+	categories map[string][2]string          // 图书 -> 中图法一级大类、二级类目
+}
+
+// NewEngine 创建本地推荐引擎，需调用 Refresh 完成首次计算
+func NewEngine(behaviorRepo repository.BehaviorRepository, circulationRepo repository.CirculationRepository, bookRepo repository.BookRepository, cfg config.FallbackConfig) *Engine {
+	return &Engine{
+		behaviorRepo:    behaviorRepo,
+		circulationRepo: circulationRepo,
+		bookRepo:        bookRepo,
+		cfg:             cfg,
+	}
+}
+
+// Ready 是否已完成首次计算
+func (e *Engine) Ready() bool {
+	return e.current() != nil
+}
+
+// RunScheduled 供定时任务调用的重建入口
+func (e *Engine) RunScheduled(ctx context.Context) {
+	if err := e.Refresh(ctx); err != nil {
+		log.Printf("本地推荐模型重建失败: %v", err)
+	}
+}
+
+// Refresh 从数据库重新计算模型，完成后整体替换；失败时保留旧模型
+func (e *Engine) Refresh(ctx context.Context) error {
+	e.refreshMu.Lock()
+	defer e.refreshMu.Unlock()
+
+	start := time.Now()
+	snap, err := e.build(ctx, start)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.snap = snap
+	e.mu.Unlock()
+	log.Printf("本地推荐模型已重建：%d 本图书，%d 位读者，耗时 %s",
+		len(snap.itemUsers), len(snap.userItems), time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// build 加载时间窗口内的行为和借阅记录并计算模型
+func (e *Engine) build(ctx context.Context, now time.Time) (*snapshot, error) {
+	snap := &snapshot{
+		builtAt:    now,
+		itemUsers:  make(map[string]map[string]float64),
+		userItems:  make(map[string]map[string]float64),
+		userRecent: make(map[string][]string),
+		itemNorms:  make(map[string]float64),
+		categories: make(map[string][2]string),
+	}
+
+	lastSeen := make(map[string]map[string]time.Time) // 读者 -> 图书 -> 最近交互时间
+	addInteraction := func(userID, bookID, feedbackType string, timestamp time.Time) {
+		weight := e.decayedWeight(feedbackType, timestamp, now)
+		addWeight(snap.userItems, userID, bookID, weight)
+		addWeight(snap.itemUsers, bookID, userID, weight)
+
+		seen := lastSeen[userID]
+		if seen == nil {
+			seen = make(map[string]time.Time)
+			lastSeen[userID] = seen
+		}
+		if timestamp.After(seen[bookID]) {
+			seen[bookID] = timestamp
+		}
+	}
+
+	since := now.Add(-e.cfg.Window)
+	afterID := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		behaviors, err := e.behaviorRepo.ListInteractionsSince(since, afterID, loadPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("加载用户行为失败: %v", err)
+		}
+		for _, b := range behaviors {
+			addInteraction(b.UserID, b.BookID, b.FeedbackType, b.Timestamp)
+		}
+		if len(behaviors) < loadPageSize {
+			break
+		}
+		afterID = behaviors[len(behaviors)-1].ID
+	}
+
+	// 借阅只记录在流通记录中，不经过 user_behaviors
+	afterID = ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		loans, err := e.circulationRepo.ListLoansSince(since, afterID, loadPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("加载借阅记录失败: %v", err)
+		}
+		for _, loan := range loans {
+			addInteraction(loan.UserID, loan.BookID, model.FeedbackTypeBorrow, loan.LoanTime)
+		}
+		if len(loans) < loadPageSize {
+			break
+		}
+		afterID = loans[len(loans)-1].ID
+	}
+
+	if err := e.loadCategories(snap); err != nil {
+		return nil, err
+	}
+
+	for bookID, users := range snap.itemUsers {
+		popularity, sumSquares := 0.0, 0.0
+		for _, w := range users {
+			popularity += w
+			sumSquares += w * w
+		}
+		snap.popular = append(snap.popular, gorse.Score{Id: bookID, Score: popularity})
+		snap.itemNorms[bookID] = math.Sqrt(sumSquares)
+	}
+	sortScores(snap.popular)
+
+	for userID, seen := range lastSeen {
+		recent := make([]string, 0, len(seen))
+		for bookID := range seen {
+			recent = append(recent, bookID)
+		}
+		sort.Slice(recent, func(i, j int) bool {
+			return seen[recent[i]].After(seen[recent[j]])
+		})
+		if len(recent) > e.cfg.HistorySize {
+			recent = recent[:e.cfg.HistorySize]
+		}
+		snap.userRecent[userID] = recent
+	}
+	return snap, nil
+}
+
+// loadCategories 读取图书分类，已不在馆藏中的图书从模型中剔除
+func (e *Engine) loadCategories(snap *snapshot) error {
+	bookIDs := make([]string, 0, len(snap.itemUsers))
+	for bookID := range snap.itemUsers {
+		bookIDs = append(bookIDs, bookID)
+	}
+
+	for start := 0; start < len(bookIDs); start += categoryBatchSize {
+		end := start + categoryBatchSize
+		if end > len(bookIDs) {
+			end = len(bookIDs)
+		}
+		books, err := e.bookRepo.FindByBookIDs(bookIDs[start:end])
+		if err != nil {
+			return fmt.Errorf("读取图书分类失败: %v", err)
+		}
+		for _, book := range books {
+			snap.categories[book.BookID] = [2]string{
+				model.CLCTopClass(book.ClassificationNumber),
+				model.CLCSubClass(book.ClassificationNumber),
+			}
+		}
+	}
+
+	for bookID, users := range snap.itemUsers {
+		if _, ok := snap.categories[bookID]; ok {
+			continue
+		}
+		delete(snap.itemUsers, bookID)
+		for userID := range users {
+			delete(snap.userItems[userID], bookID)
+		}
+	}
+	return nil
+}
+
+// decayedWeight 交互权重按半衰期指数衰减
+func (e *Engine) decayedWeight(feedbackType string, timestamp, now time.Time) float64 {
+	weight, ok := feedbackWeights[feedbackType]
+	if !ok {
+		weight = 1
+	}
+	age := now.Sub(timestamp)
+	if age < 0 {
+		age = 0
+	}
+	return weight * math.Pow(0.5, float64(age)/float64(e.cfg.HalfLife))
+}
+
+// Popular 按衰减热度获取热门图书
+func (e *Engine) Popular(category string, n, offset int) ([]gorse.Score, error) {
+	snap := e.current()
+	if snap == nil {
+		return nil, ErrNotReady
+	}
+	return snap.page(snap.popular, category, n, offset), nil
+}
+
+// Similar 按共同读者的余弦相似度获取相似图书
+func (e *Engine) Similar(bookID, category string, n, offset int) ([]gorse.Score, error) {
+	snap := e.current()
+	if snap == nil {
+		return nil, ErrNotReady
+	}
+	return snap.page(toScores(snap.neighbors(bookID)), category, n, offset), nil
+}
+
+// Recommend 以读者最近交互的图书为种子，按交互权重加权累加相似度，排除已交互的图书
+// 读者没有交互记录时返回空结果，由调用方改用热门推荐
+func (e *Engine) Recommend(userID, category string, n, offset int) ([]gorse.Score, error) {
+	snap := e.current()
+	if snap == nil {
+		return nil, ErrNotReady
+	}
+
+	history := snap.userItems[userID]
+	scores := make(map[string]float64)
+	for _, seed := range snap.userRecent[userID] {
+		weight := history[seed]
+		for bookID, similarity := range snap.neighbors(seed) {
+			if _, seen := history[bookID]; !seen {
+				scores[bookID] += weight * similarity
+			}
+		}
+	}
+	return snap.page(toScores(scores), category, n, offset), nil
+}
+
+// current 返回当前模型
+func (e *Engine) current() *snapshot {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.snap
+}
+
+// neighbors 计算与 bookID 有共同读者的图书及其余弦相似度
+func (s *snapshot) neighbors(bookID string) map[string]float64 {
+	norm := s.itemNorms[bookID]
+	if norm == 0 {
+		return nil
+	}
+
+	dots := make(map[string]float64)
+	for userID, weight := range s.itemUsers[bookID] {
+		for other, otherWeight := range s.userItems[userID] {
+			if other != bookID {
+				dots[other] += weight * otherWeight
+			}
+		}
+	}
+	for other, dot := range dots {
+		dots[other] = dot / (norm * s.itemNorms[other])
+	}
+	return dots
+}
+
+// page 按分类过滤后分页
+func (s *snapshot) page(scores []gorse.Score, category string, n, offset int) []gorse.Score {
+	result := make([]gorse.Score, 0, n)
+	skipped := 0
+	for _, score := range scores {
+		if len(result) >= n {
+			break
+		}
+		if category != "" {
+			classes := s.categories[score.Id]
+			if classes[0] != category && classes[1] != category {
+				continue
+			}
+		}
+		if skipped < offset {
+			skipped++
+			continue
+		}
+		result = append(result, score)
+	}
+	return result
+}
+
+// addWeight 累加二级映射中的权重
+func addWeight(m map[string]map[string]float64, outer, inner string, weight float64) {
+	row := m[outer]
+	if row == nil {
+		row = make(map[string]float64)
+		m[outer] = row
+	}
+	row[inner] += weight
+}
+
+// toScores 将分数映射转换为降序列表
+func toScores(m map[string]float64) []gorse.Score {
+	scores := make([]gorse.Score, 0, len(m))
+	for id, score := range m {
+		scores = append(scores, gorse.Score{Id: id, Score: score})
+	}
+	sortScores(scores)
+	return scores
+}
+
+// sortScores 按分数降序排序，分数相同按编号排序保证分页稳定
+func sortScores(scores []gorse.Score) {
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return scores[i].Id < scores[j].Id
+	})
+}
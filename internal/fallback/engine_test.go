@@ -0,0 +1,92 @@
+package fallback
+
+import (
+	"library/config"
+	"library/internal/gorse"
+	"library/internal/model"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSnapshotPage(t *testing.T) {
+	snap := &snapshot{
+		categories: map[string][2]string{
+			"a": {"T", "TP"},
+			"b": {"I", "I2"},
+			"c": {"T", "TN"},
+			"d": {"T", "TP"},
+			"e": {"I", "I2"},
+		},
+	}
+	scores := []gorse.Score{{Id: "a"}, {Id: "b"}, {Id: "c"}, {Id: "d"}, {Id: "e"}, {Id: "f"}}
+
+	tests := []struct {
+		name     string
+		category string
+		n        int
+		offset   int
+		want     []string
+	}{
+		{"不限分类", "", 3, 0, []string{"a", "b", "c"}},
+		{"不限分类第二页", "", 3, 3, []string{"d", "e", "f"}},
+		{"超出末尾", "", 3, 6, []string{}},
+		{"一级大类", "T", 10, 0, []string{"a", "c", "d"}},
+		{"一级大类分页", "T", 2, 1, []string{"c", "d"}},
+		{"二级类目", "TP", 10, 0, []string{"a", "d"}},
+		{"无分类信息的图书不匹配", "I", 10, 0, []string{"b", "e"}},
+		{"没有匹配的分类", "Z", 10, 0, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, score := range snap.page(scores, tt.category, tt.n, tt.offset) {
+				got = append(got, score.Id)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("page(%q, %d, %d) = %v，期望 %v", tt.category, tt.n, tt.offset, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecayedWeight(t *testing.T) {
+	e := &Engine{cfg: config.FallbackConfig{HalfLife: 24 * time.Hour}}
+	now := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		feedbackType string
+		age          time.Duration
+		want         float64
+	}{
+		{"浏览", "view", 0, 1},
+		{"点击", "click", 0, 2},
+		{"阅读", "read", 0, 4},
+		{"借阅", model.FeedbackTypeBorrow, 0, 5},
+		{"未知类型", "share", 0, 1},
+		{"一个半衰期", model.FeedbackTypeBorrow, 24 * time.Hour, 2.5},
+		{"两个半衰期", "read", 48 * time.Hour, 1},
+		{"未来时间不放大", "click", -time.Hour, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := e.decayedWeight(tt.feedbackType, now.Add(-tt.age), now)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("decayedWeight(%q, %s) = %v，期望 %v", tt.feedbackType, tt.age, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortScores(t *testing.T) {
+	scores := []gorse.Score{{Id: "c", Score: 1}, {Id: "a", Score: 2}, {Id: "b", Score: 1}}
+	sortScores(scores)
+	want := []gorse.Score{{Id: "a", Score: 2}, {Id: "b", Score: 1}, {Id: "c", Score: 1}}
+	if !reflect.DeepEqual(scores, want) {
+		t.Errorf("sortScores = %v，期望 %v", scores, want)
+	}
+}
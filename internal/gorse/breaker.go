@@ -0,0 +1,119 @@
+package gorse
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitState 熔断器状态
+type CircuitState int
+
+const (
+	// CircuitClosed 正常放行请求
+	CircuitClosed CircuitState = iota
+	// CircuitOpen 连续失败达到阈值，冷却期内直接拒绝请求
+	CircuitOpen
+	// CircuitHalfOpen 冷却期结束，放行一个探测请求，成功则关闭，失败则重新打开
+	CircuitHalfOpen
+)
+
+// String 返回状态的字符串表示
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// circuitBreaker 按连续失败次数熔断，threshold 为0时不熔断
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool // 半开状态下是否已有探测请求在途
+}
+
+// newCircuitBreaker 创建熔断器
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// allow 判断是否放行请求，放行后调用方必须以 record 或 release 报告结果
+func (b *circuitBreaker) allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.currentState() {
+	case CircuitOpen:
+		return false
+	case CircuitHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+	}
+	return true
+}
+
+// record 报告请求结果，failed 为 true 表示 Gorse 不可用（网络错误、超时、5xx）
+func (b *circuitBreaker) record(failed bool) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if !failed {
+		b.state = CircuitClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+		b.state = CircuitOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// release 放行的请求因调用方取消而没有结果时调用，不计入成功或失败
+func (b *circuitBreaker) release() {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// State 返回当前状态
+func (b *circuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.currentState()
+}
+
+// currentState 计算当前状态，打开且冷却期已过时视为半开，需持有锁
+func (b *circuitBreaker) currentState() CircuitState {
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.cooldown {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
@@ -0,0 +1,122 @@
+package gorse
+
+import (
+	"testing"
+	"time"
+)
+
+// expire 将打开时间提前到冷却期之前，模拟冷却期结束
+func (b *circuitBreaker) expire() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.openedAt = time.Now().Add(-b.cooldown)
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	b := newCircuitBreaker(3, time.Minute)
+
+	// 连续失败未达阈值时保持关闭，成功后重新计数
+	for i := 0; i < 2; i++ {
+		if !b.allow() {
+			t.Fatalf("关闭状态应放行请求")
+		}
+		b.record(true)
+	}
+	b.allow()
+	b.record(false)
+	for i := 0; i < 2; i++ {
+		b.allow()
+		b.record(true)
+	}
+	if got := b.State(); got != CircuitClosed {
+		t.Fatalf("成功后失败次数应清零，状态 = %s", got)
+	}
+
+	// 达到阈值后打开并拒绝请求
+	b.allow()
+	b.record(true)
+	if got := b.State(); got != CircuitOpen {
+		t.Fatalf("连续失败 3 次后状态 = %s，期望 open", got)
+	}
+	if b.allow() {
+		t.Fatalf("打开状态不应放行请求")
+	}
+
+	// 冷却期结束后半开，只放行一个探测请求
+	b.expire()
+	if got := b.State(); got != CircuitHalfOpen {
+		t.Fatalf("冷却期结束后状态 = %s，期望 half_open", got)
+	}
+	if !b.allow() {
+		t.Fatalf("半开状态应放行探测请求")
+	}
+	if b.allow() {
+		t.Fatalf("探测请求在途时不应放行其他请求")
+	}
+
+	// 探测失败重新打开
+	b.record(true)
+	if got := b.State(); got != CircuitOpen {
+		t.Fatalf("探测失败后状态 = %s，期望 open", got)
+	}
+
+	// 探测被取消时不计结果，可再次探测
+	b.expire()
+	b.allow()
+	b.release()
+	if got := b.State(); got != CircuitHalfOpen {
+		t.Fatalf("探测取消后状态 = %s，期望 half_open", got)
+	}
+	if !b.allow() {
+		t.Fatalf("探测取消后应允许再次探测")
+	}
+
+	// 探测成功后关闭
+	b.record(false)
+	if got := b.State(); got != CircuitClosed {
+		t.Fatalf("探测成功后状态 = %s，期望 closed", got)
+	}
+	if !b.allow() {
+		t.Fatalf("关闭状态应放行请求")
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	b := newCircuitBreaker(0, time.Minute)
+	for i := 0; i < 10; i++ {
+		if !b.allow() {
+			t.Fatalf("阈值为0时不应熔断")
+		}
+		b.record(true)
+	}
+	if got := b.State(); got != CircuitClosed {
+		t.Errorf("阈值为0时状态 = %s，期望 closed", got)
+	}
+}
+
+func TestAPIErrorClassification(t *testing.T) {
+	tests := []struct {
+		status    int
+		temporary bool
+		rejected  bool
+		notFound  bool
+	}{
+		{400, false, true, false},
+		{401, false, false, false},
+		{403, false, false, false},
+		{404, false, true, true},
+		{408, false, false, false},
+		{422, false, true, false},
+		{429, true, false, false},
+		{500, true, false, false},
+		{503, true, false, false},
+	}
+
+	for _, tt := range tests {
+		err := &APIError{StatusCode: tt.status}
+		if err.Temporary() != tt.temporary || err.Rejected() != tt.rejected || err.NotFound() != tt.notFound {
+			t.Errorf("状态码 %d: Temporary=%v Rejected=%v NotFound=%v，期望 %v %v %v",
+				tt.status, err.Temporary(), err.Rejected(), err.NotFound(), tt.temporary, tt.rejected, tt.notFound)
+		}
+	}
+}
@@ -2,10 +2,14 @@ package gorse
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
+	"net/url"
 	"time"
 )
 
@@ -14,23 +18,73 @@ type Client struct {
 	endpoint string
 	apiKey   string
 	client   *http.Client
+	opts     Options
+	breaker  *circuitBreaker
 }
 
+// Timeouts 各类接口的单次调用超时，为0时仅受 http.Client 的整体超时限制
+type Timeouts struct {
+	Request   time.Duration // http.Client 整体超时，为0时使用10秒
+	Feedback  time.Duration // 写入反馈
+	Recommend time.Duration // 个性化推荐
+	Popular   time.Duration // 热门物品
+	Neighbors time.Duration // 相似物品
+	Latest    time.Duration // 最新物品
+}
+
+// Options 客户端选项，零值表示不重试、不熔断
+type Options struct {
+	Timeouts         Timeouts
+	MaxRetries       int           // GET 请求遇到网络错误、5xx 或429时的重试次数
+	RetryBackoff     time.Duration // 首次重试的退避时间，之后指数增长，实际等待在 [d/2, d) 间随机
+	BreakerThreshold int           // 连续失败多少次后熔断，为0时不熔断
+	BreakerCooldown  time.Duration // 熔断后经过多久放行探测请求
+}
+
+// maxRetryBackoff 单次重试退避的上限
+const maxRetryBackoff = 2 * time.Second
+
 // NewClient 创建新的 Gorse 客户端
 func NewClient(endpoint, apiKey string) *Client {
+	return NewClientWithOptions(endpoint, apiKey, Options{})
+}
+
+// NewClientWithOptions 创建按接口设置调用超时、重试和熔断的 Gorse 客户端
+func NewClientWithOptions(endpoint, apiKey string, opts Options) *Client {
+	if opts.Timeouts.Request <= 0 {
+		opts.Timeouts.Request = 10 * time.Second
+	}
 	return &Client{
 		endpoint: endpoint,
 		apiKey:   apiKey,
 		client: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout: opts.Timeouts.Request,
 		},
+		opts:    opts,
+		breaker: newCircuitBreaker(opts.BreakerThreshold, opts.BreakerCooldown),
+	}
+}
+
+// CircuitState 返回熔断器当前状态，服务层据此决定是否直接使用降级方案
+func (c *Client) CircuitState() CircuitState {
+	return c.breaker.State()
+}
+
+// withTimeout 在 ctx 上叠加单次调用超时，timeout 为0时只继承 ctx 的取消
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
 	}
+	return context.WithTimeout(ctx, timeout)
 }
 
 // InsertFeedback 插入用户反馈数据
 func (c *Client) InsertFeedback(feedbackType, userID, itemID string, timestamp int64, extra map[string]interface{}) error {
-	url := fmt.Sprintf("%s/api/feedback", c.endpoint)
+	return c.InsertFeedbackContext(context.Background(), feedbackType, userID, itemID, timestamp, extra)
+}
 
+// InsertFeedbackContext 插入用户反馈数据，ctx 取消或超过反馈超时后中止请求
+func (c *Client) InsertFeedbackContext(ctx context.Context, feedbackType, userID, itemID string, timestamp int64, extra map[string]interface{}) error {
 	feedback := map[string]interface{}{
 		"FeedbackType": feedbackType,
 		"UserId":       userID,
@@ -42,109 +96,387 @@ func (c *Client) InsertFeedback(feedbackType, userID, itemID string, timestamp i
 		feedback["Extra"] = extra
 	}
 
-	jsonData, err := json.Marshal(feedback)
+	return c.doJSON(ctx, c.opts.Timeouts.Feedback, "POST", fmt.Sprintf("%s/api/feedback", c.endpoint), feedback, nil)
+}
+
+// InsertFeedbacks 通过Gorse批量反馈接口一次性插入多条反馈
+func (c *Client) InsertFeedbacks(feedbacks []Feedback) error {
+	return c.InsertFeedbacksContext(context.Background(), feedbacks)
+}
+
+// InsertFeedbacksContext 批量插入反馈，ctx 取消或超过反馈超时后中止请求
+func (c *Client) InsertFeedbacksContext(ctx context.Context, feedbacks []Feedback) error {
+	if len(feedbacks) == 0 {
+		return nil
+	}
+
+	return c.doJSON(ctx, c.opts.Timeouts.Feedback, "POST", fmt.Sprintf("%s/api/feedback", c.endpoint), feedbacks, nil)
+}
+
+// ListFeedback 分页导出反馈数据，返回下一页游标，游标为空表示已到末尾
+func (c *Client) ListFeedback(cursor string, n int) (string, []Feedback, error) {
+	query := url.Values{}
+	query.Set("n", fmt.Sprintf("%d", n))
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+
+	var page struct {
+		Cursor   string     `json:"Cursor"`
+		Feedback []Feedback `json:"Feedback"`
+	}
+	if err := c.doJSON(context.Background(), 0, "GET", fmt.Sprintf("%s/api/feedback?%s", c.endpoint, query.Encode()), nil, &page); err != nil {
+		return "", nil, err
+	}
+	return page.Cursor, page.Feedback, nil
+}
+
+// DeleteFeedback 删除某个用户对某个物品的全部反馈
+func (c *Client) DeleteFeedback(userID, itemID string) error {
+	apiURL := fmt.Sprintf("%s/api/feedback/%s/%s", c.endpoint, url.PathEscape(userID), url.PathEscape(itemID))
+	return c.doJSON(context.Background(), 0, "DELETE", apiURL, nil, nil)
+}
+
+// InsertItem 插入物品，物品已存在时整体覆盖
+func (c *Client) InsertItem(item Item) error {
+	return c.doJSON(context.Background(), 0, "POST", fmt.Sprintf("%s/api/item", c.endpoint), item, nil)
+}
+
+// InsertItems 批量插入物品，已存在的物品整体覆盖
+func (c *Client) InsertItems(items []Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return c.doJSON(context.Background(), 0, "POST", fmt.Sprintf("%s/api/items", c.endpoint), items, nil)
+}
+
+// UpdateItem 更新物品的全部字段（Gorse插入接口为覆盖语义）
+func (c *Client) UpdateItem(item Item) error {
+	return c.InsertItem(item)
+}
+
+// PatchItem 局部更新物品，仅修改 patch 中非空的字段
+func (c *Client) PatchItem(itemID string, patch ItemPatch) error {
+	return c.doJSON(context.Background(), 0, "PATCH", fmt.Sprintf("%s/api/item/%s", c.endpoint, url.PathEscape(itemID)), patch, nil)
+}
+
+// GetItem 获取物品
+func (c *Client) GetItem(itemID string) (*Item, error) {
+	var item Item
+	if err := c.doJSON(context.Background(), 0, "GET", fmt.Sprintf("%s/api/item/%s", c.endpoint, url.PathEscape(itemID)), nil, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// DeleteItem 删除物品及其反馈
+func (c *Client) DeleteItem(itemID string) error {
+	return c.doJSON(context.Background(), 0, "DELETE", fmt.Sprintf("%s/api/item/%s", c.endpoint, url.PathEscape(itemID)), nil, nil)
+}
+
+// InsertUser 插入用户，用户已存在时整体覆盖
+func (c *Client) InsertUser(user User) error {
+	return c.doJSON(context.Background(), 0, "POST", fmt.Sprintf("%s/api/user", c.endpoint), user, nil)
+}
+
+// InsertUsers 批量插入用户
+func (c *Client) InsertUsers(users []User) error {
+	if len(users) == 0 {
+		return nil
+	}
+	return c.doJSON(context.Background(), 0, "POST", fmt.Sprintf("%s/api/users", c.endpoint), users, nil)
+}
+
+// UpdateUser 更新用户的全部字段（Gorse插入接口为覆盖语义）
+func (c *Client) UpdateUser(user User) error {
+	return c.InsertUser(user)
+}
+
+// PatchUser 局部更新用户，仅修改 patch 中非空的字段
+func (c *Client) PatchUser(userID string, patch UserPatch) error {
+	return c.doJSON(context.Background(), 0, "PATCH", fmt.Sprintf("%s/api/user/%s", c.endpoint, url.PathEscape(userID)), patch, nil)
+}
+
+// GetUser 获取用户
+func (c *Client) GetUser(userID string) (*User, error) {
+	var user User
+	if err := c.doJSON(context.Background(), 0, "GET", fmt.Sprintf("%s/api/user/%s", c.endpoint, url.PathEscape(userID)), nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// DeleteUser 删除用户及其反馈
+func (c *Client) DeleteUser(userID string) error {
+	return c.doJSON(context.Background(), 0, "DELETE", fmt.Sprintf("%s/api/user/%s", c.endpoint, url.PathEscape(userID)), nil, nil)
+}
+
+// doJSON 发送JSON请求，body 和 out 可为空
+func (c *Client) doJSON(ctx context.Context, timeout time.Duration, method, apiURL string, body interface{}, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("序列化请求数据失败: %v", err)
+		}
+	}
+
+	data, err := c.send(ctx, timeout, method, apiURL, payload)
 	if err != nil {
-		return fmt.Errorf("序列化反馈数据失败: %v", err)
+		return err
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("解析响应失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// send 发送请求并返回响应体，GET 请求在可重试的错误后按抖动退避重试
+// 非200响应返回 *APIError，熔断期间返回 ErrCircuitOpen
+func (c *Client) send(ctx context.Context, timeout time.Duration, method, apiURL string, payload []byte) ([]byte, error) {
+	attempts := 1
+	if method == http.MethodGet {
+		attempts += c.opts.MaxRetries
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, lastErr
+			case <-time.After(c.retryDelay(attempt)):
+			}
+		}
+
+		data, err := c.sendOnce(ctx, timeout, method, apiURL, payload)
+		if err == nil {
+			return data, nil
+		}
+		lastErr = err
+		if !retryable(ctx, err) {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// sendOnce 经过熔断器发送一次请求
+func (c *Client) sendOnce(ctx context.Context, timeout time.Duration, method, apiURL string, payload []byte) ([]byte, error) {
+	if !c.breaker.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	reqCtx, cancel := withTimeout(ctx, timeout)
+	defer cancel()
+
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(reqCtx, method, apiURL, reader)
 	if err != nil {
-		return fmt.Errorf("创建请求失败: %v", err)
+		c.breaker.release()
+		return nil, fmt.Errorf("创建请求失败: %w", err)
 	}
 
 	req.Header.Set("X-API-Key", c.apiKey)
-	req.Header.Set("Content-Type", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("发送请求失败: %v", err)
+		// 调用方取消不代表Gorse不可用，单次调用超时则计入失败
+		if ctx.Err() != nil {
+			c.breaker.release()
+		} else {
+			c.breaker.record(true)
+		}
+		return nil, fmt.Errorf("发送请求失败: %w", err)
 	}
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
 		if err != nil {
-			fmt.Printf("关闭响应体失败: %v", err)
+			fmt.Println("关闭响应体失败:", err)
 		}
 	}(resp.Body)
 
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.breaker.record(ctx.Err() == nil)
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("API返回错误状态码: %d", resp.StatusCode)
+		if len(data) > maxErrorBodySize {
+			data = data[:maxErrorBodySize]
+		}
+		apiErr := &APIError{
+			Method:     method,
+			Path:       req.URL.Path,
+			StatusCode: resp.StatusCode,
+			Body:       string(bytes.TrimSpace(data)),
+		}
+		c.breaker.record(apiErr.Temporary())
+		return nil, apiErr
 	}
 
-	return nil
+	c.breaker.record(false)
+	return data, nil
 }
 
-// GetRecommend 获取个性化推荐
-func (c *Client) GetRecommend(userID string, category string, n int) ([]string, error) {
-	url := fmt.Sprintf("%s/api/recommend/%s?n=%d", c.endpoint, userID, n)
-	if category != "" {
-		url += "&category=" + category
+// retryDelay 第 attempt 次重试前的等待时间，指数增长并加随机抖动
+func (c *Client) retryDelay(attempt int) time.Duration {
+	delay := c.opts.RetryBackoff << (attempt - 1)
+	if delay <= 0 || delay > maxRetryBackoff {
+		delay = maxRetryBackoff
 	}
-	return c.getItems(url)
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
 }
 
-// GetPopular 获取热门图书
-func (c *Client) GetPopular(category string, n int) ([]string, error) {
-	url := fmt.Sprintf("%s/api/popular?n=%d", c.endpoint, n)
-	if category != "" {
-		url += "&category=" + category
+// retryable 判断请求失败后是否值得重试：调用方已取消或熔断时不重试，4xx 不重试
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
 	}
-	return c.getItems(url)
+	return true
+}
+
+// GetRecommend 获取个性化推荐，offset 用于分页获取更多候选
+func (c *Client) GetRecommend(userID string, category string, n, offset int) ([]Score, error) {
+	return c.GetRecommendContext(context.Background(), userID, category, n, offset)
+}
+
+// GetRecommendContext 获取个性化推荐，ctx 取消或超过推荐超时后中止请求
+func (c *Client) GetRecommendContext(ctx context.Context, userID string, category string, n, offset int) ([]Score, error) {
+	return c.getItems(ctx, c.opts.Timeouts.Recommend, c.buildURL("/api/recommend/"+url.PathEscape(userID), category, n, offset))
+}
+
+// GetPopular 获取热门图书
+func (c *Client) GetPopular(category string, n, offset int) ([]Score, error) {
+	return c.GetPopularContext(context.Background(), category, n, offset)
+}
+
+// GetPopularContext 获取热门图书，ctx 取消或超过热门超时后中止请求
+func (c *Client) GetPopularContext(ctx context.Context, category string, n, offset int) ([]Score, error) {
+	return c.getItems(ctx, c.opts.Timeouts.Popular, c.buildURL("/api/popular", category, n, offset))
 }
 
 // GetItemNeighbors 获取相似图书
-func (c *Client) GetItemNeighbors(itemID string, category string, n int) ([]string, error) {
-	url := fmt.Sprintf("%s/api/item/%s/neighbors?n=%d", c.endpoint, itemID, n)
-	if category != "" {
-		url += "&category=" + category
-	}
-	return c.getItems(url)
+func (c *Client) GetItemNeighbors(itemID string, category string, n, offset int) ([]Score, error) {
+	return c.GetItemNeighborsContext(context.Background(), itemID, category, n, offset)
+}
+
+// GetItemNeighborsContext 获取相似图书，ctx 取消或超过相似物品超时后中止请求
+func (c *Client) GetItemNeighborsContext(ctx context.Context, itemID string, category string, n, offset int) ([]Score, error) {
+	return c.getItems(ctx, c.opts.Timeouts.Neighbors, c.buildURL("/api/item/"+url.PathEscape(itemID)+"/neighbors", category, n, offset))
 }
 
 // GetLatest 获取最新图书
-func (c *Client) GetLatest(category string, n int) ([]string, error) {
-	url := fmt.Sprintf("%s/api/latest?n=%d", c.endpoint, n)
+func (c *Client) GetLatest(category string, n, offset int) ([]Score, error) {
+	return c.GetLatestContext(context.Background(), category, n, offset)
+}
+
+// GetLatestContext 获取最新图书，ctx 取消或超过最新物品超时后中止请求
+func (c *Client) GetLatestContext(ctx context.Context, category string, n, offset int) ([]Score, error) {
+	return c.getItems(ctx, c.opts.Timeouts.Latest, c.buildURL("/api/latest", category, n, offset))
+}
+
+// buildURL 构建带 n、offset 和 category 查询参数的请求地址，路径中的ID需由调用方转义
+func (c *Client) buildURL(path string, category string, n, offset int) string {
+	query := url.Values{}
+	query.Set("n", fmt.Sprintf("%d", n))
+	if offset > 0 {
+		query.Set("offset", fmt.Sprintf("%d", offset))
+	}
 	if category != "" {
-		url += "&category=" + category
+		query.Set("category", category)
 	}
-	return c.getItems(url)
+	return fmt.Sprintf("%s%s?%s", c.endpoint, path, query.Encode())
 }
 
 // getItems 通用的获取项目列表方法
-func (c *Client) getItems(url string) ([]string, error) {
-	req, err := http.NewRequest("GET", url, nil)
+// 兼容两种响应格式：带分数的 [{"Id":..,"Score":..}] 与仅含ID的 ["id", ...]，后者分数为0
+func (c *Client) getItems(ctx context.Context, timeout time.Duration, apiURL string) ([]Score, error) {
+	data, err := c.send(ctx, timeout, http.MethodGet, apiURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("X-API-Key", c.apiKey)
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			fmt.Println("关闭响应体失败:", err)
-		}
-	}(resp.Body)
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API返回错误状态码: %d", resp.StatusCode)
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("解析推荐结果失败: %v", err)
 	}
 
-	var items []string
-	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
-		return nil, err
+	items := make([]Score, 0, len(raw))
+	for _, element := range raw {
+		var score Score
+		if err := json.Unmarshal(element, &score); err != nil {
+			var id string
+			if err := json.Unmarshal(element, &id); err != nil {
+				return nil, fmt.Errorf("解析推荐结果失败: %v", err)
+			}
+			score.Id = id
+		}
+		items = append(items, score)
 	}
 
 	return items, nil
 }
 
+// Score 带推荐分数的物品
+type Score struct {
+	Id    string  `json:"Id"`
+	Score float64 `json:"Score"`
+}
+
 // Feedback 用户反馈结构
 type Feedback struct {
-	FeedbackType string    `json:"FeedbackType"`
-	UserId       string    `json:"UserId"`
-	ItemId       string    `json:"ItemId"`
-	Timestamp    time.Time `json:"Timestamp"`
+	FeedbackType string                 `json:"FeedbackType"`
+	UserId       string                 `json:"UserId"`
+	ItemId       string                 `json:"ItemId"`
+	Timestamp    time.Time              `json:"Timestamp"`
+	Extra        map[string]interface{} `json:"Extra,omitempty"`
+}
+
+// Item Gorse物品结构
+type Item struct {
+	ItemId     string   `json:"ItemId"`
+	IsHidden   bool     `json:"IsHidden"`
+	Categories []string `json:"Categories"`
+	Timestamp  string   `json:"Timestamp"` // RFC3339 格式
+	Labels     []string `json:"Labels"`
+	Comment    string   `json:"Comment"`
+}
+
+// ItemPatch 物品局部更新，nil 字段保持不变
+type ItemPatch struct {
+	IsHidden   *bool    `json:"IsHidden,omitempty"`
+	Categories []string `json:"Categories,omitempty"`
+	Timestamp  *string  `json:"Timestamp,omitempty"`
+	Labels     []string `json:"Labels,omitempty"`
+	Comment    *string  `json:"Comment,omitempty"`
+}
+
+// User Gorse用户结构
+type User struct {
+	UserId    string   `json:"UserId"`
+	Labels    []string `json:"Labels"`
+	Subscribe []string `json:"Subscribe"`
+	Comment   string   `json:"Comment"`
+}
+
+// UserPatch 用户局部更新，nil 字段保持不变
+type UserPatch struct {
+	Labels    []string `json:"Labels,omitempty"`
+	Subscribe []string `json:"Subscribe,omitempty"`
+	Comment   *string  `json:"Comment,omitempty"`
 }
@@ -0,0 +1,60 @@
+package gorse
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// maxErrorBodySize APIError 中保留的响应体最大长度
+const maxErrorBodySize = 1024
+
+// ErrCircuitOpen 熔断器打开，Gorse 近期连续失败，请求未发出直接返回
+var ErrCircuitOpen = errors.New("Gorse熔断中，暂停请求")
+
+// APIError Gorse 返回的非200响应
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string // 响应体，超过 maxErrorBodySize 时截断
+}
+
+// Error 返回错误描述
+func (e *APIError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("Gorse %s %s 返回错误状态码: %d", e.Method, e.Path, e.StatusCode)
+	}
+	return fmt.Sprintf("Gorse %s %s 返回错误状态码: %d: %s", e.Method, e.Path, e.StatusCode, e.Body)
+}
+
+// NotFound 用户或物品不存在
+func (e *APIError) NotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// Temporary 服务端错误或限流，稍后重试可能成功
+func (e *APIError) Temporary() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+}
+
+// Rejected 请求内容被拒绝（4xx，不含认证失败、请求超时和限流），原样重试不会成功
+func (e *APIError) Rejected() bool {
+	switch e.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	}
+	return e.StatusCode >= 400 && e.StatusCode < 500
+}
+
+// IsRejected 判断 err 是否为 Gorse 因请求内容拒绝的错误
+func IsRejected(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Rejected()
+}
+
+// IsNotFound 判断 err 是否为 Gorse 返回的404
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.NotFound()
+}
@@ -1,9 +1,13 @@
 package model
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
 	"time"
 
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 // BookStatus 图书状态枚举
@@ -80,8 +84,12 @@ type BookInfo struct {
 	DistributionUnit     string          `json:"distribution_unit"`
 	Notes                string          `json:"notes"`
 	Status               BookStatus      `json:"status" gorm:"type:varchar(20);default:'available'"`
+	ContentHash          string          `json:"-" gorm:"size:64"`                // 编目字段摘要，用于增量同步判断记录是否变化
+	SyncedAt             *time.Time      `json:"-" gorm:"index"`                  // 最近一次在上游数据中出现的同步轮次开始时间
+	RemovedBySync        bool            `json:"-" gorm:"not null;default:false"` // 因上游删除被同步标记为丢失，上游重新出现时恢复为可借
 	CreatedAt            time.Time       `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt            time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt            gorm.DeletedAt  `json:"-" gorm:"index"`
 }
 
 // TableName 指定表名
@@ -89,6 +97,28 @@ func (BookInfo) TableName() string {
 	return "book_information"
 }
 
+// BookDetail 图书详情，附带可借状态和相似图书编号
+type BookDetail struct {
+	*BookInfo
+	Available      bool     `json:"available"`
+	SimilarBookIDs []string `json:"similar_book_ids"`
+}
+
+// CatalogHash 计算来自数据平台的编目字段摘要，本地维护的状态和时间字段不参与计算
+func (b *BookInfo) CatalogHash() string {
+	publicationDate := ""
+	if !b.PublicationDate.IsZero() {
+		publicationDate = b.PublicationDate.Format("2006-01-02")
+	}
+	fields := []string{
+		b.BookID, b.BookBarcode, b.Title, b.PublicationNumber,
+		b.PrimaryAuthor, b.ClassificationNumber, b.LanguageCode, b.Edition,
+		b.Publisher, b.PublicationPlace, publicationDate, b.DistributionUnit, b.Notes,
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x1f")))
+	return hex.EncodeToString(sum[:])
+}
+
 // APIBookInfo 从API获取的图书信息结构
 type APIBookInfo struct {
 	ID                   string `json:"id"`
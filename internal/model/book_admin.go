@@ -0,0 +1,140 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// publicationDateLayout 出版日期格式
+const publicationDateLayout = "2006-01-02"
+
+// BookCreateRequest 馆员新增本地馆藏的请求
+type BookCreateRequest struct {
+	BookID               string     `json:"book_id" binding:"required"`
+	BookBarcode          string     `json:"book_barcode" binding:"required"`
+	Title                string     `json:"title" binding:"required"`
+	PublicationNumber    string     `json:"publication_number"`
+	PrimaryAuthor        string     `json:"primary_author"`
+	ClassificationNumber string     `json:"classification_number"`
+	LanguageCode         string     `json:"language_code"`
+	Edition              string     `json:"edition"`
+	Publisher            string     `json:"publisher"`
+	PublicationPlace     string     `json:"publication_place"`
+	PublicationDate      string     `json:"publication_date"` // 格式 2006-01-02，可为空
+	DistributionUnit     string     `json:"distribution_unit"`
+	Notes                string     `json:"notes"`
+	Status               BookStatus `json:"status"` // 为空时为 available
+}
+
+// ToBookInfo 校验请求并转换为数据库模型，主键由仓储分配
+func (r *BookCreateRequest) ToBookInfo() (*BookInfo, error) {
+	book := &BookInfo{
+		BookID:               strings.TrimSpace(r.BookID),
+		BookBarcode:          strings.TrimSpace(r.BookBarcode),
+		Title:                strings.TrimSpace(r.Title),
+		PublicationNumber:    r.PublicationNumber,
+		PrimaryAuthor:        r.PrimaryAuthor,
+		ClassificationNumber: r.ClassificationNumber,
+		LanguageCode:         r.LanguageCode,
+		Edition:              r.Edition,
+		Publisher:            r.Publisher,
+		PublicationPlace:     r.PublicationPlace,
+		DistributionUnit:     r.DistributionUnit,
+		Notes:                r.Notes,
+		Status:               r.Status,
+	}
+	if book.Status == "" {
+		book.Status = BookStatusAvailable
+	}
+
+	var err error
+	if book.PublicationDate, err = ParsePublicationDate(r.PublicationDate); err != nil {
+		return nil, err
+	}
+	if err := book.Validate(); err != nil {
+		return nil, err
+	}
+	return book, nil
+}
+
+// BookUpdateRequest 馆员修改图书的请求，nil 字段保持不变
+// UpdatedAt 必须等于读取时的 updated_at，期间被他人修改过时拒绝更新
+type BookUpdateRequest struct {
+	BookBarcode          *string     `json:"book_barcode"`
+	Title                *string     `json:"title"`
+	PublicationNumber    *string     `json:"publication_number"`
+	PrimaryAuthor        *string     `json:"primary_author"`
+	ClassificationNumber *string     `json:"classification_number"`
+	LanguageCode         *string     `json:"language_code"`
+	Edition              *string     `json:"edition"`
+	Publisher            *string     `json:"publisher"`
+	PublicationPlace     *string     `json:"publication_place"`
+	PublicationDate      *string     `json:"publication_date"` // 格式 2006-01-02，空字符串表示清空
+	DistributionUnit     *string     `json:"distribution_unit"`
+	Notes                *string     `json:"notes"`
+	Status               *BookStatus `json:"status"`
+	UpdatedAt            time.Time   `json:"updated_at" binding:"required"`
+}
+
+// Apply 将修改应用到图书并校验结果，图书编号作为推荐物品ID不允许修改
+func (r *BookUpdateRequest) Apply(book *BookInfo) error {
+	setString := func(dst *string, src *string) {
+		if src != nil {
+			*dst = strings.TrimSpace(*src)
+		}
+	}
+	setString(&book.BookBarcode, r.BookBarcode)
+	setString(&book.Title, r.Title)
+	setString(&book.PublicationNumber, r.PublicationNumber)
+	setString(&book.PrimaryAuthor, r.PrimaryAuthor)
+	setString(&book.ClassificationNumber, r.ClassificationNumber)
+	setString(&book.LanguageCode, r.LanguageCode)
+	setString(&book.Edition, r.Edition)
+	setString(&book.Publisher, r.Publisher)
+	setString(&book.PublicationPlace, r.PublicationPlace)
+	setString(&book.DistributionUnit, r.DistributionUnit)
+	setString(&book.Notes, r.Notes)
+	if r.Status != nil {
+		book.Status = *r.Status
+		book.RemovedBySync = false // 馆员设置的状态不再由同步恢复
+	}
+	if r.PublicationDate != nil {
+		date, err := ParsePublicationDate(*r.PublicationDate)
+		if err != nil {
+			return err
+		}
+		book.PublicationDate = date
+	}
+	return book.Validate()
+}
+
+// Validate 校验馆员维护的图书字段
+func (b *BookInfo) Validate() error {
+	if b.BookID == "" {
+		return fmt.Errorf("book_id 不能为空")
+	}
+	if b.BookBarcode == "" {
+		return fmt.Errorf("book_barcode 不能为空")
+	}
+	if b.Title == "" {
+		return fmt.Errorf("title 不能为空")
+	}
+	if !b.Status.IsValid() {
+		return fmt.Errorf("无效的图书状态: %q", b.Status)
+	}
+	return nil
+}
+
+// ParsePublicationDate 解析出版日期，空字符串返回零值（与同步时无法解析的日期一致）
+func ParsePublicationDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.Parse(publicationDateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("出版日期格式应为 YYYY-MM-DD: %q", value)
+	}
+	return date, nil
+}
@@ -0,0 +1,129 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FeedbackTypeBorrow 借阅反馈类型，需在Gorse配置中列为正反馈
+const FeedbackTypeBorrow = "borrow"
+
+// CirculationRecord 图书流通（借还）记录
+type CirculationRecord struct {
+	ID          string     `json:"id" gorm:"primaryKey;size:64"` // 数据平台记录ID，缺失时由读者、条形码和借出时间生成
+	UserID      string     `json:"user_id" gorm:"not null;index"`
+	BookID      string     `json:"book_id" gorm:"not null;index:idx_circulation_book,priority:1"`
+	BookBarcode string     `json:"book_barcode"`
+	LoanTime    time.Time  `json:"loan_time" gorm:"not null;index:idx_circulation_book,priority:2"`
+	DueTime     *time.Time `json:"due_time,omitempty"`
+	ReturnTime  *time.Time `json:"return_time,omitempty"` // 为空表示尚未归还
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (CirculationRecord) TableName() string {
+	return "circulation_records"
+}
+
+// Returned 是否已归还
+func (r *CirculationRecord) Returned() bool {
+	return r.ReturnTime != nil && !r.ReturnTime.IsZero()
+}
+
+// OutboxID 借阅反馈在发件箱中的事件ID，与用户行为ID区分
+func (r *CirculationRecord) OutboxID() string {
+	return "circulation:" + r.ID
+}
+
+// NewBorrowOutbox 根据流通记录构建借阅反馈，时间戳取借出时间
+func NewBorrowOutbox(record *CirculationRecord) *FeedbackOutbox {
+	return &FeedbackOutbox{
+		BehaviorID:    record.OutboxID(),
+		FeedbackType:  FeedbackTypeBorrow,
+		UserID:        record.UserID,
+		ItemID:        record.BookID,
+		Timestamp:     record.LoanTime,
+		Status:        OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+}
+
+// APICirculationRecord 从数据平台获取的流通记录结构
+type APICirculationRecord struct {
+	ID          string `json:"id"`
+	UserID      string `json:"dzzh"`  // 读者证号
+	BookID      string `json:"tsbh"`  // 图书编号
+	BookBarcode string `json:"tstxm"` // 图书条形码
+	LoanTime    string `json:"jcsj"`  // 借出时间
+	DueTime     string `json:"yhsj"`  // 应还时间
+	ReturnTime  string `json:"ghsj"`  // 归还时间，未归还时为空
+}
+
+// circulationTimeLayouts 流通记录中可能出现的时间格式
+var circulationTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006/01/02 15:04:05",
+	"2006-01-02",
+}
+
+// ParseCirculationTime 解析流通记录中的时间，按本地时区处理不带时区的格式；空字符串返回 nil
+func ParseCirculationTime(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range circulationTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("无法解析时间: %q", value)
+}
+
+// ToCirculationRecord 将数据平台记录转换为数据库模型，缺少读者、图书或借出时间时返回错误
+func (a *APICirculationRecord) ToCirculationRecord() (*CirculationRecord, error) {
+	if a.UserID == "" {
+		return nil, fmt.Errorf("缺少读者证号")
+	}
+	if a.BookID == "" && a.BookBarcode == "" {
+		return nil, fmt.Errorf("缺少图书编号和条形码")
+	}
+
+	loanTime, err := ParseCirculationTime(a.LoanTime)
+	if err != nil {
+		return nil, err
+	}
+	if loanTime == nil {
+		return nil, fmt.Errorf("缺少借出时间")
+	}
+	dueTime, err := ParseCirculationTime(a.DueTime)
+	if err != nil {
+		return nil, err
+	}
+	returnTime, err := ParseCirculationTime(a.ReturnTime)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &CirculationRecord{
+		ID:          a.ID,
+		UserID:      a.UserID,
+		BookID:      a.BookID,
+		BookBarcode: a.BookBarcode,
+		LoanTime:    *loanTime,
+		DueTime:     dueTime,
+		ReturnTime:  returnTime,
+	}
+	if record.ID == "" {
+		// 同一读者同一时间借出同一册书只会有一条记录
+		sum := sha256.Sum256([]byte(a.UserID + "\x1f" + a.BookBarcode + "\x1f" + a.BookID + "\x1f" + loanTime.UTC().Format(time.RFC3339)))
+		record.ID = hex.EncodeToString(sum[:16])
+	}
+	return record, nil
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCirculationTime(t *testing.T) {
+	want := time.Date(2026, 10, 17, 9, 30, 0, 0, time.Local)
+	tests := []struct {
+		value string
+		want  *time.Time
+		err   bool
+	}{
+		{"2026-10-17 09:30:00", &want, false},
+		{"2026-10-17T09:30:00", &want, false},
+		{"2026/10/17 09:30:00", &want, false},
+		{" 2026-10-17 09:30:00 ", &want, false},
+		{"2026-10-17T09:30:00+08:00", ptrTime(time.Date(2026, 10, 17, 9, 30, 0, 0, time.FixedZone("", 8*3600))), false},
+		{"2026-10-17", ptrTime(time.Date(2026, 10, 17, 0, 0, 0, 0, time.Local)), false},
+		{"", nil, false},
+		{"17/10/2026", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseCirculationTime(tt.value)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseCirculationTime(%q) 错误 = %v，期望返回错误: %v", tt.value, err, tt.err)
+			continue
+		}
+		switch {
+		case tt.want == nil && got != nil:
+			t.Errorf("ParseCirculationTime(%q) = %s，期望 nil", tt.value, got)
+		case tt.want != nil && (got == nil || !got.Equal(*tt.want)):
+			t.Errorf("ParseCirculationTime(%q) = %v，期望 %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestToCirculationRecord(t *testing.T) {
+	tests := []struct {
+		name   string
+		record APICirculationRecord
+		err    bool
+	}{
+		{"完整记录", APICirculationRecord{ID: "1", UserID: "u1", BookID: "b1", LoanTime: "2026-10-17 09:30:00", ReturnTime: "2026-10-20 10:00:00"}, false},
+		{"只有条形码", APICirculationRecord{UserID: "u1", BookBarcode: "c1", LoanTime: "2026-10-17"}, false},
+		{"缺少读者", APICirculationRecord{BookID: "b1", LoanTime: "2026-10-17"}, true},
+		{"缺少图书", APICirculationRecord{UserID: "u1", LoanTime: "2026-10-17"}, true},
+		{"缺少借出时间", APICirculationRecord{UserID: "u1", BookID: "b1"}, true},
+		{"归还时间无法解析", APICirculationRecord{UserID: "u1", BookID: "b1", LoanTime: "2026-10-17", ReturnTime: "昨天"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record, err := tt.record.ToCirculationRecord()
+			if (err != nil) != tt.err {
+				t.Fatalf("返回错误 %v，期望返回错误: %v", err, tt.err)
+			}
+			if err == nil && record.ID == "" {
+				t.Errorf("记录ID为空")
+			}
+		})
+	}
+
+	// 没有ID的记录按内容生成稳定的ID
+	a := APICirculationRecord{UserID: "u1", BookBarcode: "c1", LoanTime: "2026-10-17 09:30:00"}
+	first, _ := a.ToCirculationRecord()
+	second, _ := a.ToCirculationRecord()
+	a.LoanTime = "2026-10-18 09:30:00"
+	other, _ := a.ToCirculationRecord()
+	if first.ID != second.ID || first.ID == other.ID {
+		t.Errorf("生成的记录ID不稳定或不唯一: %s %s %s", first.ID, second.ID, other.ID)
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,98 @@
+package model
+
+import "strings"
+
+// CLCTopClass 返回中图法分类号的一级大类（如 "TP311.13" -> "T"）
+func CLCTopClass(classificationNumber string) string {
+	cn := strings.ToUpper(strings.TrimSpace(classificationNumber))
+	if cn == "" || !isCLCLetter(cn[0]) {
+		return ""
+	}
+	return cn[:1]
+}
+
+// CLCSubClass 返回中图法分类号的二级类目
+// 工业技术(T)类以双字母表示二级类目（如 "TP"），其余大类取字母加首位数字（如 "I2"）
+func CLCSubClass(classificationNumber string) string {
+	cn := strings.ToUpper(strings.TrimSpace(classificationNumber))
+	if len(cn) < 2 || !isCLCLetter(cn[0]) {
+		return ""
+	}
+	if next := cn[1]; isCLCLetter(next) || ('0' <= next && next <= '9') {
+		return cn[:2]
+	}
+	return ""
+}
+
+// isCLCLetter 中图法类目字母只使用ASCII大写字母，按字节判断，多字节字符的首字节不会误判
+func isCLCLetter(b byte) bool {
+	return 'A' <= b && b <= 'Z'
+}
+
+// clcClassNames 中图法一级大类及工业技术(T)类二级类目名称
+var clcClassNames = map[string]string{
+	"A":  "马克思主义、列宁主义、毛泽东思想、邓小平理论",
+	"B":  "哲学、宗教",
+	"C":  "社会科学总论",
+	"D":  "政治、法律",
+	"E":  "军事",
+	"F":  "经济",
+	"G":  "文化、科学、教育、体育",
+	"H":  "语言、文字",
+	"I":  "文学",
+	"J":  "艺术",
+	"K":  "历史、地理",
+	"N":  "自然科学总论",
+	"O":  "数理科学和化学",
+	"P":  "天文学、地球科学",
+	"Q":  "生物科学",
+	"R":  "医药、卫生",
+	"S":  "农业科学",
+	"T":  "工业技术",
+	"U":  "交通运输",
+	"V":  "航空、航天",
+	"X":  "环境科学、安全科学",
+	"Z":  "综合性图书",
+	"TB": "一般工业技术",
+	"TD": "矿业工程",
+	"TE": "石油、天然气工业",
+	"TF": "冶金工业",
+	"TG": "金属学与金属工艺",
+	"TH": "机械、仪表工业",
+	"TJ": "武器工业",
+	"TK": "能源与动力工程",
+	"TL": "原子能技术",
+	"TM": "电工技术",
+	"TN": "无线电电子学、电信技术",
+	"TP": "自动化技术、计算机技术",
+	"TQ": "化学工业",
+	"TS": "轻工业、手工业",
+	"TU": "建筑科学",
+	"TV": "水利工程",
+}
+
+// CLCCategoryName 返回分类代码对应的名称，未知时返回空字符串
+func CLCCategoryName(code string) string {
+	return clcClassNames[code]
+}
+
+// NormalizeCLCCategory 规范化推荐分类参数，仅接受一级大类或二级类目代码
+func NormalizeCLCCategory(category string) (string, bool) {
+	code := strings.ToUpper(strings.TrimSpace(category))
+	switch len(code) {
+	case 1:
+		return code, CLCTopClass(code) == code
+	case 2:
+		return code, CLCSubClass(code) == code
+	default:
+		return "", false
+	}
+}
+
+// CLCCategory 推荐分类及其馆藏数量
+type CLCCategory struct {
+	Code          string         `json:"code"`
+	Name          string         `json:"name"`
+	Count         int64          `json:"count"`
+	Subcategories []*CLCCategory `json:"subcategories,omitempty"`
+}
@@ -0,0 +1,57 @@
+package model
+
+import "testing"
+
+func TestCLCClasses(t *testing.T) {
+	tests := []struct {
+		name string
+		cn   string
+		top  string
+		sub  string
+	}{
+		{"工业技术双字母", "TP311.13", "T", "TP"},
+		{"字母加数字", "I247.5", "I", "I2"},
+		{"小写与空白", "  tp391 ", "T", "TP"},
+		{"只有大类", "Z", "Z", ""},
+		{"第二位为符号", "K-09", "K", ""},
+		{"空字符串", "", "", ""},
+		{"数字开头", "123", "", ""},
+		{"非ASCII字母开头", "é12", "", ""},
+		{"全角字母开头", "ＴＰ311", "", ""},
+		{"第二位为非ASCII字母", "Té", "T", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CLCTopClass(tt.cn); got != tt.top {
+				t.Errorf("CLCTopClass(%q) = %q，期望 %q", tt.cn, got, tt.top)
+			}
+			if got := CLCSubClass(tt.cn); got != tt.sub {
+				t.Errorf("CLCSubClass(%q) = %q，期望 %q", tt.cn, got, tt.sub)
+			}
+		})
+	}
+}
+
+func TestNormalizeCLCCategory(t *testing.T) {
+	tests := []struct {
+		category string
+		want     string
+		ok       bool
+	}{
+		{"t", "T", true},
+		{" TP ", "TP", true},
+		{"I2", "I2", true},
+		{"TP3", "", false},
+		{"", "", false},
+		{"2", "2", false},
+		{"é", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := NormalizeCLCCategory(tt.category)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("NormalizeCLCCategory(%q) = %q, %v，期望 %q, %v", tt.category, got, ok, tt.want, tt.ok)
+		}
+	}
+}
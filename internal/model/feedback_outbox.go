@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// OutboxStatus 发件箱记录状态
+type OutboxStatus string
+
+const (
+	OutboxStatusPending OutboxStatus = "pending" // 等待投递
+	OutboxStatusDead    OutboxStatus = "dead"    // 超过最大重试次数，进入死信
+)
+
+// FeedbackOutbox 待投递到Gorse的反馈发件箱
+// 行为入库时在同一事务中写入，由后台投递任务异步发送，成功后删除
+type FeedbackOutbox struct {
+	ID            uint         `json:"id" gorm:"primaryKey;autoIncrement"`
+	BehaviorID    string       `json:"behavior_id" gorm:"uniqueIndex"`
+	FeedbackType  string       `json:"feedback_type" gorm:"not null"`
+	UserID        string       `json:"user_id" gorm:"not null"`
+	ItemID        string       `json:"item_id" gorm:"not null"`
+	Timestamp     time.Time    `json:"timestamp" gorm:"not null"`
+	Extra         string       `json:"extra"`
+	Status        OutboxStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending';index:idx_outbox_due,priority:1"`
+	Attempts      int          `json:"attempts"`
+	NextAttemptAt time.Time    `json:"next_attempt_at" gorm:"not null;index:idx_outbox_due,priority:2"`
+	LastError     string       `json:"last_error"`
+	CreatedAt     time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (FeedbackOutbox) TableName() string {
+	return "feedback_outbox"
+}
+
+// ExtraMap 将Extra字段解析为map
+func (o *FeedbackOutbox) ExtraMap() map[string]interface{} {
+	return parseExtra(o.Extra)
+}
+
+// NewFeedbackOutbox 根据行为记录构建发件箱记录
+func NewFeedbackOutbox(behavior *UserBehavior) *FeedbackOutbox {
+	return &FeedbackOutbox{
+		BehaviorID:    behavior.ID,
+		FeedbackType:  behavior.FeedbackType,
+		UserID:        behavior.UserID,
+		ItemID:        behavior.BookID,
+		Timestamp:     behavior.Timestamp,
+		Extra:         behavior.Extra,
+		Status:        OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+}
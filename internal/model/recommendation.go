@@ -10,9 +10,32 @@ type RecommendationResponse struct {
 
 // RecommendationCategory 推荐类别
 type RecommendationCategory struct {
-	ID          string      `json:"id"`
-	Name        string      `json:"name"`
-	Description string      `json:"description"`
-	Books       []*BookInfo `json:"books"`
-	Total       int         `json:"total"`
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Books       []*RecommendedBook `json:"books"`
+	Total       int                `json:"total"`
+	Error       string             `json:"error,omitempty"`  // 该栏目获取失败或超时的原因
+	Source      RecommendSource    `json:"source,omitempty"` // 该栏目使用的推荐源
 }
+
+// RecommendedBook 推荐结果中的单本图书，附带实时可借状态
+type RecommendedBook struct {
+	*BookInfo
+	Rank      int             `json:"rank"`      // 在推荐结果中的排名，从1开始
+	Score     float64         `json:"score"`     // 推荐引擎给出的分数
+	Available bool            `json:"available"` // 当前是否可借
+	Source    RecommendSource `json:"source"`    // 给出该推荐的推荐源
+}
+
+// RecommendSource 推荐结果来源
+type RecommendSource string
+
+const (
+	// RecommendSourceGorse 来自Gorse推荐引擎
+	RecommendSourceGorse RecommendSource = "gorse"
+	// RecommendSourceLocal 来自基于本地行为数据的降级推荐
+	RecommendSourceLocal RecommendSource = "local"
+	// RecommendSourceBlend Gorse与本地推荐按权重融合的结果
+	RecommendSourceBlend RecommendSource = "blend"
+)
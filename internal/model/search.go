@@ -0,0 +1,34 @@
+package model
+
+// BookSearchQuery 馆藏检索条件
+type BookSearchQuery struct {
+	Q              string `form:"q"`              // 关键词，空格分隔的多个词需同时命中题名、作者、出版社、图书编号、条形码或出版号之一
+	Classification string `form:"classification"` // 中图法类目，按分类号前缀匹配，如 "I"、"TP"
+	Language       string `form:"language"`       // 语种码
+	Publisher      string `form:"publisher"`      // 出版社，精确匹配
+	Year           int    `form:"year"`           // 出版年份
+	Page           int    `form:"page"`
+	PageSize       int    `form:"page_size"`
+}
+
+// FacetBucket 分面统计项
+type FacetBucket struct {
+	Value string `json:"value"`
+	Name  string `json:"name,omitempty"` // 可读名称，如中图法类目名称
+	Count int64  `json:"count"`
+}
+
+// SearchFacets 检索结果的分面统计，按当前检索条件过滤后计算
+type SearchFacets struct {
+	Classifications []FacetBucket `json:"classifications"` // 中图法一级大类
+	Languages       []FacetBucket `json:"languages"`
+	Publishers      []FacetBucket `json:"publishers"`
+	Years           []FacetBucket `json:"years"`
+}
+
+// BookSearchResponse 馆藏检索响应
+type BookSearchResponse struct {
+	BatchBookResponse
+	Query  string        `json:"query"`
+	Facets *SearchFacets `json:"facets"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// SyncCheckpoint 数据同步断点，记录最后一个已完成的页码，重启后从下一页继续
+type SyncCheckpoint struct {
+	Source    string    `json:"source" gorm:"primaryKey"` // 同步来源，如 catalog_books
+	LastPage  int       `json:"last_page"`                // 最后一个已完成的页码
+	Finished  bool      `json:"finished"`                 // 上一轮是否已遍历完全部页
+	StartedAt time.Time `json:"started_at"`               // 当前一轮的开始时间，断点续传时沿用，用于判定上游已删除的记录
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (SyncCheckpoint) TableName() string {
+	return "sync_checkpoints"
+}
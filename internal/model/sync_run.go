@@ -0,0 +1,62 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SyncRunStatus 同步任务执行状态
+type SyncRunStatus string
+
+const (
+	SyncRunRunning   SyncRunStatus = "running"   // 执行中
+	SyncRunSucceeded SyncRunStatus = "succeeded" // 成功
+	SyncRunFailed    SyncRunStatus = "failed"    // 失败
+	SyncRunCanceled  SyncRunStatus = "canceled"  // 因服务关闭而中断
+)
+
+// SyncTrigger 同步任务触发方式
+type SyncTrigger string
+
+const (
+	SyncTriggerSchedule SyncTrigger = "schedule" // 定时触发
+	SyncTriggerManual   SyncTrigger = "manual"   // 管理接口手动触发
+)
+
+// SyncRun 数据同步任务的执行记录
+type SyncRun struct {
+	ID         string        `json:"id" gorm:"primaryKey;size:32"`
+	Source     string        `json:"source" gorm:"index:idx_sync_run_source,priority:1"` // 同步来源，如 catalog_books
+	Trigger    SyncTrigger   `json:"trigger" gorm:"type:varchar(20)"`
+	Status     SyncRunStatus `json:"status" gorm:"type:varchar(20)"`
+	Pages      int           `json:"pages"`
+	Fetched    int           `json:"fetched"`
+	Inserted   int           `json:"inserted"`
+	Updated    int           `json:"updated"`
+	Unchanged  int           `json:"unchanged"`
+	Skipped    int           `json:"skipped"`
+	Removed    int           `json:"removed"`
+	Error      string        `json:"error,omitempty"`
+	StartedAt  time.Time     `json:"started_at" gorm:"index:idx_sync_run_source,priority:2"`
+	FinishedAt *time.Time    `json:"finished_at,omitempty"`
+}
+
+// TableName 指定表名
+func (SyncRun) TableName() string {
+	return "sync_runs"
+}
+
+// BeforeCreate 未指定主键时自动生成任务ID
+func (r *SyncRun) BeforeCreate(tx *gorm.DB) error {
+	if r.ID != "" {
+		return nil
+	}
+	id, err := newRandomID()
+	if err != nil {
+		return fmt.Errorf("生成任务ID失败: %v", err)
+	}
+	r.ID = id
+	return nil
+}
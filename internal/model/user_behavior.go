@@ -1,6 +1,15 @@
 package model
 
-import "time"
+import (
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // BehaviorType 用户行为类型
 type BehaviorType string
@@ -8,23 +17,27 @@ type BehaviorType string
 const (
 	BehaviorClick    BehaviorType = "click"     // 点击
 	BehaviorView     BehaviorType = "view"      // 浏览
+	BehaviorRead     BehaviorType = "read"      // 阅读
 	BehaviorStayTime BehaviorType = "stay_time" // 停留时间
 )
 
 // UserBehavior 用户行为记录
 type UserBehavior struct {
-	ID          string       `json:"id" gorm:"primaryKey"`
-	UserID      string       `json:"user_id" gorm:"not null;index"`
-	BookID      string       `json:"book_id" gorm:"not null;index"`
-	Type        BehaviorType `json:"type" gorm:"not null"`
-	Element     string       `json:"element"`                   // 交互的元素
-	Position    Position     `json:"position" gorm:"type:json"` // 点击位置
-	ScrollDepth int          `json:"scroll_depth"`              // 滚动深度(%)
-	StayTime    int          `json:"stay_time"`                 // 停留时间(秒)
-	Timestamp   time.Time    `json:"timestamp" gorm:"not null;index"`
-	Extra       string       `json:"extra"` // 额外信息（JSON格式）
-	CreatedAt   time.Time    `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt   time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+	ID           string       `json:"id" gorm:"primaryKey"`
+	UserID       string       `json:"user_id" gorm:"not null;index"`
+	BookID       string       `json:"book_id" gorm:"not null;index"`
+	BookTitle    string       `json:"book_title"`
+	Type         BehaviorType `json:"type" gorm:"not null"`
+	FeedbackType string       `json:"feedback_type"`             // 转发给Gorse的反馈类型
+	Element      string       `json:"element"`                   // 交互的元素
+	Position     Position     `json:"position" gorm:"type:json"` // 点击位置
+	ScrollDepth  int          `json:"scroll_depth"`              // 滚动深度(%)
+	StayTime     int          `json:"stay_time"`                 // 停留时间(秒)
+	Timestamp    time.Time    `json:"timestamp" gorm:"not null;index"`
+	Extra        string       `json:"extra"`                               // 额外信息（JSON格式）
+	ForwardedAt  *time.Time   `json:"forwarded_at,omitempty" gorm:"index"` // 成功转发到Gorse的时间，为空表示待重放
+	CreatedAt    time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
 // TableName 指定表名
@@ -32,8 +45,73 @@ func (UserBehavior) TableName() string {
 	return "user_behaviors"
 }
 
+// BeforeCreate 未指定主键时自动生成行为ID
+func (b *UserBehavior) BeforeCreate(tx *gorm.DB) error {
+	if b.ID != "" {
+		return nil
+	}
+	id, err := newRandomID()
+	if err != nil {
+		return fmt.Errorf("生成行为ID失败: %v", err)
+	}
+	b.ID = id
+	return nil
+}
+
+// newRandomID 生成32位十六进制随机ID
+func newRandomID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// ExtraMap 将Extra字段解析为map
+func (b *UserBehavior) ExtraMap() map[string]interface{} {
+	return parseExtra(b.Extra)
+}
+
+// parseExtra 解析JSON格式的额外信息，为空或格式错误时返回nil
+func parseExtra(raw string) map[string]interface{} {
+	if raw == "" {
+		return nil
+	}
+	var extra map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &extra); err != nil {
+		return nil
+	}
+	return extra
+}
+
 // Position 点击位置
 type Position struct {
 	X int `json:"x"`
 	Y int `json:"y"`
 }
+
+// Value 实现 driver.Valuer，以JSON格式存储
+func (p Position) Value() (driver.Value, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan 实现 sql.Scanner，从JSON列读取
+func (p *Position) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*p = Position{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("无法解析点击位置: %T", value)
+	}
+	return json.Unmarshal(data, p)
+}
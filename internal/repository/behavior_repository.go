@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"library/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BehaviorRepository 用户行为仓储接口
+type BehaviorRepository interface {
+	CreateBehaviors(behaviors []*model.UserBehavior) error
+	CreateWithOutbox(behaviors []*model.UserBehavior) error
+	GetBehaviorByID(id string) (*model.UserBehavior, error)
+	ListByUser(userID string, limit int) ([]*model.UserBehavior, error)
+	ListRequeueable(limit int) ([]*model.UserBehavior, error)
+	MarkForwarded(ids []string, forwardedAt time.Time) error
+	BackfillBookIDs() (int64, error)
+	ListInteractionsSince(since time.Time, afterID string, limit int) ([]*model.UserBehavior, error)
+}
+
+// PostgresBehaviorRepository PostgreSQL实现
+type PostgresBehaviorRepository struct {
+	db *gorm.DB
+}
+
+func NewBehaviorRepository(db *gorm.DB) BehaviorRepository {
+	return &PostgresBehaviorRepository{db: db}
+}
+
+// CreateBehaviors 批量保存行为记录
+func (r *PostgresBehaviorRepository) CreateBehaviors(behaviors []*model.UserBehavior) error {
+	if len(behaviors) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(behaviors, 500).Error
+}
+
+// CreateWithOutbox 在同一事务中保存行为记录并写入反馈发件箱
+func (r *PostgresBehaviorRepository) CreateWithOutbox(behaviors []*model.UserBehavior) error {
+	if len(behaviors) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(behaviors, 500).Error; err != nil {
+			return err
+		}
+
+		entries := make([]*model.FeedbackOutbox, 0, len(behaviors))
+		for _, behavior := range behaviors {
+			entries = append(entries, model.NewFeedbackOutbox(behavior))
+		}
+		return enqueueOutbox(tx, entries)
+	})
+}
+
+func (r *PostgresBehaviorRepository) GetBehaviorByID(id string) (*model.UserBehavior, error) {
+	var behavior model.UserBehavior
+	err := r.db.Where("id = ?", id).First(&behavior).Error
+	if err != nil {
+		return nil, err
+	}
+	return &behavior, nil
+}
+
+// ListByUser 按时间倒序获取用户的行为记录
+func (r *PostgresBehaviorRepository) ListByUser(userID string, limit int) ([]*model.UserBehavior, error) {
+	var behaviors []*model.UserBehavior
+	err := r.db.Where("user_id = ?", userID).
+		Order("timestamp DESC").
+		Limit(limit).
+		Find(&behaviors).Error
+	if err != nil {
+		return nil, err
+	}
+	return behaviors, nil
+}
+
+// ListRequeueable 按时间顺序获取尚未转发到Gorse、且没有发件箱记录或发件箱记录已进入死信的行为记录
+// 等待投递或投递中的记录由投递任务处理，不重复入队
+func (r *PostgresBehaviorRepository) ListRequeueable(limit int) ([]*model.UserBehavior, error) {
+	var behaviors []*model.UserBehavior
+	err := r.db.Where("forwarded_at IS NULL").
+		Where("NOT EXISTS (SELECT 1 FROM feedback_outbox o WHERE o.behavior_id = user_behaviors.id AND o.status <> ?)", model.OutboxStatusDead).
+		Order("timestamp ASC").
+		Limit(limit).
+		Find(&behaviors).Error
+	if err != nil {
+		return nil, err
+	}
+	return behaviors, nil
+}
+
+// ListInteractionsSince 按主键分页获取 since 之后有图书编号的行为记录，只读取计算推荐所需的字段
+func (r *PostgresBehaviorRepository) ListInteractionsSince(since time.Time, afterID string, limit int) ([]*model.UserBehavior, error) {
+	var behaviors []*model.UserBehavior
+	err := r.db.Select("id, user_id, book_id, type, feedback_type, timestamp").
+		Where("timestamp >= ? AND id > ? AND book_id <> ''", since, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&behaviors).Error
+	if err != nil {
+		return nil, err
+	}
+	return behaviors, nil
+}
+
+// MarkForwarded 标记行为记录已转发到Gorse
+func (r *PostgresBehaviorRepository) MarkForwarded(ids []string, forwardedAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&model.UserBehavior{}).
+		Where("id IN ?", ids).
+		Update("forwarded_at", forwardedAt).Error
+}
+
+// BackfillBookIDs 为仅记录了标题的历史行为补全图书编号，同名图书取最早入库的记录
+func (r *PostgresBehaviorRepository) BackfillBookIDs() (int64, error) {
+	result := r.db.Exec(`
+		UPDATE user_behaviors ub SET book_id = b.book_id
+		FROM (
+			SELECT DISTINCT ON (title) title, book_id
+			FROM book_information
+			ORDER BY title, id
+		) b
+		WHERE ub.book_id = '' AND ub.book_title = b.title`)
+	return result.RowsAffected, result.Error
+}
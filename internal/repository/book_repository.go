@@ -1,9 +1,14 @@
 package repository
 
 import (
+	"errors"
 	"library/internal/model"
+	"strings"
+	"time"
 
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // BookRepository 图书仓储接口
@@ -13,9 +18,44 @@ type BookRepository interface {
 	GetBookInfoByBarcode(barcode string) (*model.BookInfo, error)
 	UpdateBookInfo(book *model.BookInfo) error
 	FindByIDs(ids []string) ([]*model.BookInfo, error)
+	GetBookByBookID(bookID string) (*model.BookInfo, error)
+	FindByBookIDs(bookIDs []string) ([]*model.BookInfo, error)
 	BatchGetBookInfo(pageSize, pageNumber int, ids []string) ([]*model.BookInfo, int64, error)
 	GetBookByTitle(title string) (*model.BookInfo, error)
 	BatchGetBooksByTitles(titles []string) ([]*model.BookInfo, int64, error)
+	ListAfterID(lastID decimal.Decimal, limit int) ([]*model.BookInfo, error)
+	CountByClassification() (map[string]int64, error)
+	FindByBarcodes(barcodes []string) ([]*model.BookInfo, error)
+	UpsertBooks(books []*model.BookInfo) error
+	TouchSynced(bookIDs []string, syncedAt time.Time) error
+	RestoreRemovedBySync(bookIDs []string) ([]string, error)
+	CountNotSyncedSince(since time.Time, softDelete bool) (int64, error)
+	RemoveNotSyncedSince(since time.Time, softDelete bool) ([]string, error)
+	ListUpdatedSince(since time.Time, lastID decimal.Decimal, limit int) ([]*model.BookInfo, error)
+	ListDeletedSince(since time.Time, lastID decimal.Decimal, limit int) ([]*model.BookInfo, error)
+	LastPublishedAt() (*time.Time, error)
+	SavePublishedAt(publishedAt time.Time) error
+	SearchBooks(query *model.BookSearchQuery) ([]*model.BookInfo, int64, error)
+	SearchFacets(query *model.BookSearchQuery) (*model.SearchFacets, error)
+	NextLocalID() (decimal.Decimal, error)
+	FindConflicting(bookID, barcode string, excludeID decimal.Decimal) ([]*model.BookInfo, error)
+	UpdateBookIfUnmodified(book *model.BookInfo, updatedAt time.Time) (bool, error)
+	DeleteBookIfUnmodified(bookID string, updatedAt *time.Time) (bool, error)
+	ListLatest(category string, limit, offset int) ([]*model.BookInfo, error)
+}
+
+// publishCheckpointSource 馆藏发布到Gorse的进度名称
+const publishCheckpointSource = "gorse_items"
+
+// localBookIDBase 馆员新增的本地馆藏的主键起点，远大于数据平台的记录ID，避免之后同步时主键冲突
+var localBookIDBase = decimal.New(1, 20)
+
+// editableBookColumns 馆员可修改的字段，图书编号作为推荐物品ID不允许修改
+var editableBookColumns = []string{
+	"book_barcode", "title", "publication_number", "primary_author",
+	"classification_number", "language_code", "edition", "publisher",
+	"publication_place", "publication_date", "distribution_unit", "notes",
+	"status", "removed_by_sync", "updated_at",
 }
 
 // PostgresBookRepository PostgreSQL实现
@@ -62,19 +102,38 @@ func (r *PostgresBookRepository) FindByIDs(ids []string) ([]*model.BookInfo, err
 	return books, nil
 }
 
-// BatchGetBookInfo 批量获取图书信息（支持分页）
+func (r *PostgresBookRepository) GetBookByBookID(bookID string) (*model.BookInfo, error) {
+	var book model.BookInfo
+	err := r.db.Where("book_id = ?", bookID).First(&book).Error
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+func (r *PostgresBookRepository) FindByBookIDs(bookIDs []string) ([]*model.BookInfo, error) {
+	var books []*model.BookInfo
+	err := r.db.Where("book_id IN ?", bookIDs).Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// BatchGetBookInfo 按图书编号批量获取图书信息（支持分页，按主键排序）
 func (r *PostgresBookRepository) BatchGetBookInfo(pageSize, pageNumber int, ids []string) ([]*model.BookInfo, int64, error) {
 	var books []*model.BookInfo
 	var total int64
 
 	// 计算总数
-	if err := r.db.Model(&model.BookInfo{}).Where("id IN ?", ids).Count(&total).Error; err != nil {
+	if err := r.db.Model(&model.BookInfo{}).Where("book_id IN ?", ids).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// 分页查询
 	offset := (pageNumber - 1) * pageSize
-	err := r.db.Where("id IN ?", ids).
+	err := r.db.Where("book_id IN ?", ids).
+		Order("id").
 		Offset(offset).
 		Limit(pageSize).
 		Find(&books).Error
@@ -111,3 +170,276 @@ func (r *PostgresBookRepository) BatchGetBooksByTitles(titles []string) ([]*mode
 
 	return books, total, nil
 }
+
+// ListAfterID 按主键顺序获取 lastID 之后的图书，用于全量遍历
+func (r *PostgresBookRepository) ListAfterID(lastID decimal.Decimal, limit int) ([]*model.BookInfo, error) {
+	var books []*model.BookInfo
+	err := r.db.Where("id > ?", lastID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// CountByClassification 统计每个分类号的图书数量
+func (r *PostgresBookRepository) CountByClassification() (map[string]int64, error) {
+	var rows []struct {
+		ClassificationNumber string
+		Count                int64
+	}
+	err := r.db.Model(&model.BookInfo{}).
+		Select("classification_number, COUNT(*) AS count").
+		Group("classification_number").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.ClassificationNumber] = row.Count
+	}
+	return counts, nil
+}
+
+// FindByBarcodes 按条形码批量查询图书，包含已软删除的记录（其条形码仍占用唯一约束）
+func (r *PostgresBookRepository) FindByBarcodes(barcodes []string) ([]*model.BookInfo, error) {
+	var books []*model.BookInfo
+	err := r.db.Unscoped().Where("book_barcode IN ?", barcodes).Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// UpsertBooks 按图书编号插入或更新馆藏数据
+// 冲突时只更新来自数据平台的编目字段，保留主键、入库时间和本地维护的借阅状态；已软删除的记录会被恢复
+func (r *PostgresBookRepository) UpsertBooks(books []*model.BookInfo) error {
+	if len(books) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "book_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"book_barcode", "title", "publication_number", "primary_author",
+			"classification_number", "language_code", "edition", "publisher",
+			"publication_place", "publication_date", "distribution_unit", "notes",
+			"content_hash", "synced_at", "updated_at", "deleted_at",
+		}),
+	}).CreateInBatches(books, 500).Error
+}
+
+// TouchSynced 更新未变化记录的同步时间，不修改 updated_at
+func (r *PostgresBookRepository) TouchSynced(bookIDs []string, syncedAt time.Time) error {
+	if len(bookIDs) == 0 {
+		return nil
+	}
+	return r.db.Model(&model.BookInfo{}).
+		Where("book_id IN ?", bookIDs).
+		UpdateColumn("synced_at", syncedAt).Error
+}
+
+// RestoreRemovedBySync 将曾因上游删除被同步标记为丢失、现已重新出现的图书恢复为可借，返回恢复的图书编号
+// 馆员手动标记的丢失不受影响
+func (r *PostgresBookRepository) RestoreRemovedBySync(bookIDs []string) ([]string, error) {
+	if len(bookIDs) == 0 {
+		return nil, nil
+	}
+	var restored []string
+	err := r.db.Raw(`
+		UPDATE book_information SET status = ?, removed_by_sync = false, updated_at = ?
+		WHERE book_id IN ? AND removed_by_sync AND status = ? AND deleted_at IS NULL
+		RETURNING book_id`,
+		model.BookStatusAvailable, time.Now(), bookIDs, model.BookStatusLost,
+	).Scan(&restored).Error
+	if err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+// notSyncedSinceScope 同步时间早于 since、待按 softDelete 处理的记录，统计与处理使用相同条件
+func notSyncedSinceScope(db *gorm.DB, since time.Time, softDelete bool) *gorm.DB {
+	db = db.Model(&model.BookInfo{}).Where("synced_at < ?", since)
+	if !softDelete {
+		db = db.Where("status <> ?", model.BookStatusLost)
+	}
+	return db
+}
+
+// CountNotSyncedSince 统计待处理的上游已删除记录数，不含未参与过同步的本地记录
+func (r *PostgresBookRepository) CountNotSyncedSince(since time.Time, softDelete bool) (int64, error) {
+	var count int64
+	err := notSyncedSinceScope(r.db, since, softDelete).Count(&count).Error
+	return count, err
+}
+
+// RemoveNotSyncedSince 处理同步时间早于 since 的记录：softDelete 为 true 时软删除，否则标记为丢失并记录为同步所致。返回受影响的图书编号
+func (r *PostgresBookRepository) RemoveNotSyncedSince(since time.Time, softDelete bool) ([]string, error) {
+	var removed []*model.BookInfo
+	now := time.Now()
+	db := notSyncedSinceScope(r.db, since, softDelete).Clauses(clause.Returning{Columns: []clause.Column{{Name: "book_id"}}})
+	var err error
+	if softDelete {
+		err = db.Delete(&removed).Error
+	} else {
+		err = db.Model(&removed).Updates(map[string]interface{}{
+			"status":          model.BookStatusLost,
+			"removed_by_sync": true,
+			"updated_at":      now,
+		}).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bookIDs := make([]string, 0, len(removed))
+	for _, book := range removed {
+		bookIDs = append(bookIDs, book.BookID)
+	}
+	return bookIDs, nil
+}
+
+// ListUpdatedSince 按主键顺序分页获取 since 之后有变化的图书
+func (r *PostgresBookRepository) ListUpdatedSince(since time.Time, lastID decimal.Decimal, limit int) ([]*model.BookInfo, error) {
+	var books []*model.BookInfo
+	err := r.db.Where("updated_at >= ? AND id > ?", since, lastID).
+		Order("id").
+		Limit(limit).
+		Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// ListDeletedSince 按主键顺序分页获取 since 之后软删除的图书
+func (r *PostgresBookRepository) ListDeletedSince(since time.Time, lastID decimal.Decimal, limit int) ([]*model.BookInfo, error) {
+	var books []*model.BookInfo
+	err := r.db.Unscoped().
+		Where("deleted_at >= ? AND id > ?", since, lastID).
+		Order("id").
+		Limit(limit).
+		Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// LastPublishedAt 返回上一次成功发布到Gorse时覆盖到的时间，从未发布过时返回 nil
+func (r *PostgresBookRepository) LastPublishedAt() (*time.Time, error) {
+	var checkpoint model.SyncCheckpoint
+	err := r.db.Where("source = ? AND finished", publishCheckpointSource).First(&checkpoint).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &checkpoint.StartedAt, nil
+}
+
+// SavePublishedAt 记录发布到Gorse覆盖到的时间，作为下一次发布的起点
+func (r *PostgresBookRepository) SavePublishedAt(publishedAt time.Time) error {
+	checkpoint := model.SyncCheckpoint{
+		Source:    publishCheckpointSource,
+		Finished:  true,
+		StartedAt: publishedAt,
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "source"}},
+		DoUpdates: clause.AssignmentColumns([]string{"finished", "started_at", "updated_at"}),
+	}).Create(&checkpoint).Error
+}
+
+// localIDSequence 本地馆藏主键相对 localBookIDBase 的偏移序列（bigint 序列无法直接表示 1e20 以上的主键）
+const localIDSequence = "book_information_local_id_seq"
+
+// EnsureLocalIDSequence 创建本地馆藏主键序列，并推进到已有本地记录（含已软删除的）之后
+func EnsureLocalIDSequence(db *gorm.DB) error {
+	if err := db.Exec("CREATE SEQUENCE IF NOT EXISTS " + localIDSequence + " AS bigint MINVALUE 1").Error; err != nil {
+		return err
+	}
+	return db.Exec(`
+		SELECT setval('`+localIDSequence+`', GREATEST(
+			(SELECT last_value FROM `+localIDSequence+`),
+			(SELECT COALESCE(MAX(id::numeric), 0) - ?::numeric FROM book_information)
+		)::bigint)`,
+		localBookIDBase.String(),
+	).Error
+}
+
+// NextLocalID 分配本地馆藏的主键，由序列保证并发新增时不重复
+func (r *PostgresBookRepository) NextLocalID() (decimal.Decimal, error) {
+	var id decimal.Decimal
+	err := r.db.Raw(
+		"SELECT ?::numeric + nextval('"+localIDSequence+"')",
+		localBookIDBase.String(),
+	).Scan(&id).Error
+	return id, err
+}
+
+// FindConflicting 查找图书编号或条形码与给定值相同的其他记录，包含已软删除的记录（仍占用唯一约束）
+func (r *PostgresBookRepository) FindConflicting(bookID, barcode string, excludeID decimal.Decimal) ([]*model.BookInfo, error) {
+	var books []*model.BookInfo
+	err := r.db.Unscoped().
+		Where("(book_id = ? OR book_barcode = ?) AND id <> ?", bookID, barcode, excludeID).
+		Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// UpdateBookIfUnmodified 仅当记录的 updated_at 仍等于 updatedAt 时更新馆员可修改的字段，返回是否更新成功
+// 编目摘要 content_hash 保持不变，上游数据本身没有变化时同步不会覆盖馆员的修正
+func (r *PostgresBookRepository) UpdateBookIfUnmodified(book *model.BookInfo, updatedAt time.Time) (bool, error) {
+	result := r.db.Model(book).
+		Where("updated_at = ?", updatedAt).
+		Select(editableBookColumns).
+		Updates(book)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteBookIfUnmodified 软删除图书，updatedAt 不为 nil 时仅当记录未被修改过才删除，返回是否删除成功
+func (r *PostgresBookRepository) DeleteBookIfUnmodified(bookID string, updatedAt *time.Time) (bool, error) {
+	db := r.db.Where("book_id = ?", bookID)
+	if updatedAt != nil {
+		db = db.Where("updated_at = ?", *updatedAt)
+	}
+	result := db.Delete(&model.BookInfo{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListLatest 按出版日期（未填写时取入库时间）倒序分页查询图书，与发布到Gorse的物品时间戳一致
+// category 为中图法一级大类或二级类目，为空时不过滤；丢失的图书在Gorse中隐藏，这里同样排除
+func (r *PostgresBookRepository) ListLatest(category string, limit, offset int) ([]*model.BookInfo, error) {
+	db := r.db.Where("status <> ?", model.BookStatusLost)
+	if category != "" {
+		db = db.Where("UPPER(classification_number) LIKE ?", escapeLike(strings.ToUpper(category))+"%")
+	}
+
+	var books []*model.BookInfo
+	err := db.
+		Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "CASE WHEN EXTRACT(YEAR FROM publication_date) > 1 THEN publication_date ELSE created_at END DESC, id DESC",
+			WithoutParentheses: true,
+		}}).
+		Offset(offset).
+		Limit(limit).
+		Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+	return books, nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"library/internal/model"
+	"log"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// searchFacetLimit 每个分面最多返回的项数
+const searchFacetLimit = 20
+
+// searchColumns 关键词检索的字段
+var searchColumns = []string{"title", "primary_author", "publisher", "book_id", "book_barcode", "publication_number"}
+
+// EnsureSearchIndexes 创建检索所需的 pg_trgm 扩展和三元组索引，使中文关键词的 ILIKE 子串匹配可以走索引
+// 数据库账号无权创建扩展时只记录警告，检索仍可用但退化为顺序扫描
+func EnsureSearchIndexes(db *gorm.DB) {
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		log.Printf("警告: 无法启用 pg_trgm 扩展，图书检索将不使用三元组索引: %v", err)
+		return
+	}
+	for _, column := range []string{"title", "primary_author", "publisher"} {
+		sql := "CREATE INDEX IF NOT EXISTS idx_book_information_" + column + "_trgm ON book_information USING gin (" + column + " gin_trgm_ops)"
+		if err := db.Exec(sql).Error; err != nil {
+			log.Printf("警告: 创建 %s 三元组索引失败: %v", column, err)
+		}
+	}
+}
+
+// SearchBooks 按检索条件分页查询图书，有关键词时题名完全匹配、前缀匹配的排在前面
+func (r *PostgresBookRepository) SearchBooks(query *model.BookSearchQuery) ([]*model.BookInfo, int64, error) {
+	var total int64
+	if err := r.searchScope(query).Model(&model.BookInfo{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	db := r.searchScope(query)
+	if q := strings.TrimSpace(query.Q); q != "" {
+		pattern := escapeLike(q)
+		db = db.Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "CASE WHEN title = ? THEN 0 WHEN title ILIKE ? THEN 1 WHEN title ILIKE ? THEN 2 ELSE 3 END, id",
+			Vars:               []interface{}{q, pattern + "%", "%" + pattern + "%"},
+			WithoutParentheses: true,
+		}})
+	} else {
+		db = db.Order("id")
+	}
+
+	var books []*model.BookInfo
+	err := db.
+		Offset((query.Page - 1) * query.PageSize).
+		Limit(query.PageSize).
+		Find(&books).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return books, total, nil
+}
+
+// SearchFacets 统计检索结果在分类、语种、出版社和出版年份上的分布
+func (r *PostgresBookRepository) SearchFacets(query *model.BookSearchQuery) (*model.SearchFacets, error) {
+	facets := &model.SearchFacets{}
+
+	var err error
+	if facets.Classifications, err = r.facet(query, "UPPER(LEFT(classification_number, 1))", "classification_number <> ''"); err != nil {
+		return nil, err
+	}
+	for i := range facets.Classifications {
+		facets.Classifications[i].Name = model.CLCCategoryName(facets.Classifications[i].Value)
+	}
+	if facets.Languages, err = r.facet(query, "language_code", "language_code <> ''"); err != nil {
+		return nil, err
+	}
+	if facets.Publishers, err = r.facet(query, "publisher", "publisher <> ''"); err != nil {
+		return nil, err
+	}
+	// 出版日期无法解析时为零值（公元1年），不计入年份分面
+	if facets.Years, err = r.facet(query, "CAST(EXTRACT(YEAR FROM publication_date) AS TEXT)", "publication_date > '0001-12-31'"); err != nil {
+		return nil, err
+	}
+	return facets, nil
+}
+
+// facet 按表达式分组计数，取数量最多的前 searchFacetLimit 项
+func (r *PostgresBookRepository) facet(query *model.BookSearchQuery, expr, notEmpty string) ([]model.FacetBucket, error) {
+	var buckets []model.FacetBucket
+	err := r.searchScope(query).Model(&model.BookInfo{}).
+		Select(expr + " AS value, COUNT(*) AS count").
+		Where(notEmpty).
+		Group(expr).
+		Order("count DESC, value").
+		Limit(searchFacetLimit).
+		Scan(&buckets).Error
+	if err != nil {
+		return nil, err
+	}
+	return buckets, nil
+}
+
+// searchScope 构建检索条件
+func (r *PostgresBookRepository) searchScope(query *model.BookSearchQuery) *gorm.DB {
+	db := r.db
+	for _, term := range strings.Fields(query.Q) {
+		pattern := "%" + escapeLike(term) + "%"
+		conditions := make([]string, 0, len(searchColumns))
+		args := make([]interface{}, 0, len(searchColumns))
+		for _, column := range searchColumns {
+			conditions = append(conditions, column+" ILIKE ?")
+			args = append(args, pattern)
+		}
+		db = db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+	if query.Classification != "" {
+		db = db.Where("UPPER(classification_number) LIKE ?", escapeLike(strings.ToUpper(query.Classification))+"%")
+	}
+	if query.Language != "" {
+		db = db.Where("language_code = ?", query.Language)
+	}
+	if query.Publisher != "" {
+		db = db.Where("publisher = ?", query.Publisher)
+	}
+	if query.Year > 0 {
+		db = db.Where("publication_date >= ? AND publication_date < ?",
+			strconv.Itoa(query.Year)+"-01-01", strconv.Itoa(query.Year+1)+"-01-01")
+	}
+	return db
+}
+
+// escapeLike 转义 LIKE 模式中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
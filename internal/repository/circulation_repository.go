@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"errors"
+	"library/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CirculationRepository 流通记录仓储接口
+type CirculationRepository interface {
+	FindExistingIDs(ids []string) ([]string, error)
+	SaveWithOutbox(records []*model.CirculationRecord, feedback []*model.FeedbackOutbox) error
+	RefreshBookStatuses(bookIDs []string) (int64, error)
+	ListLoansSince(since time.Time, afterID string, limit int) ([]*model.CirculationRecord, error)
+	LastSyncedAt() (*time.Time, error)
+	SaveSyncedAt(startedAt time.Time) error
+}
+
+// circulationCheckpointSource 流通记录同步的断点名称
+const circulationCheckpointSource = "circulation_records"
+
+// PostgresCirculationRepository PostgreSQL实现
+type PostgresCirculationRepository struct {
+	db *gorm.DB
+}
+
+func NewCirculationRepository(db *gorm.DB) CirculationRepository {
+	return &PostgresCirculationRepository{db: db}
+}
+
+// FindExistingIDs 返回已入库的记录ID
+func (r *PostgresCirculationRepository) FindExistingIDs(ids []string) ([]string, error) {
+	var existing []string
+	err := r.db.Model(&model.CirculationRecord{}).
+		Where("id IN ?", ids).
+		Pluck("id", &existing).Error
+	if err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// SaveWithOutbox 在同一事务中按ID写入流通记录（已存在时更新应还、归还时间）并写入借阅反馈发件箱
+func (r *PostgresCirculationRepository) SaveWithOutbox(records []*model.CirculationRecord, feedback []*model.FeedbackOutbox) error {
+	if len(records) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"due_time", "return_time", "updated_at"}),
+		}).CreateInBatches(records, 500).Error
+		if err != nil {
+			return err
+		}
+		if len(feedback) == 0 {
+			return nil
+		}
+		return enqueueOutbox(tx, feedback)
+	})
+}
+
+// RefreshBookStatuses 按最近一次借出记录更新图书状态：未归还为已借出，已归还为可借
+// 只在可借与已借出之间切换，不覆盖预约、维护、丢失等人工维护的状态。返回状态有变化的图书数
+func (r *PostgresCirculationRepository) RefreshBookStatuses(bookIDs []string) (int64, error) {
+	if len(bookIDs) == 0 {
+		return 0, nil
+	}
+	result := r.db.Exec(`
+		UPDATE book_information b
+		SET status = latest.status, updated_at = ?
+		FROM (
+			SELECT DISTINCT ON (book_id) book_id,
+				CASE WHEN return_time IS NULL THEN ? ELSE ? END AS status
+			FROM circulation_records
+			WHERE book_id IN ?
+			ORDER BY book_id, loan_time DESC
+		) latest
+		WHERE b.book_id = latest.book_id
+			AND b.status IN ?
+			AND b.status <> latest.status`,
+		time.Now(), model.BookStatusBorrowed, model.BookStatusAvailable, bookIDs,
+		[]model.BookStatus{model.BookStatusAvailable, model.BookStatusBorrowed},
+	)
+	return result.RowsAffected, result.Error
+}
+
+// ListLoansSince 按主键分页获取 since 之后借出的记录，只读取计算推荐所需的字段
+func (r *PostgresCirculationRepository) ListLoansSince(since time.Time, afterID string, limit int) ([]*model.CirculationRecord, error) {
+	var records []*model.CirculationRecord
+	err := r.db.Select("id, user_id, book_id, loan_time").
+		Where("loan_time >= ? AND id > ?", since, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// LastSyncedAt 返回上一次完整同步的开始时间，从未完整同步过时返回 nil
+func (r *PostgresCirculationRepository) LastSyncedAt() (*time.Time, error) {
+	var checkpoint model.SyncCheckpoint
+	err := r.db.Where("source = ? AND finished", circulationCheckpointSource).First(&checkpoint).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &checkpoint.StartedAt, nil
+}
+
+// SaveSyncedAt 记录一次完整同步的开始时间，作为下一次增量同步的起点
+func (r *PostgresCirculationRepository) SaveSyncedAt(startedAt time.Time) error {
+	checkpoint := model.SyncCheckpoint{
+		Source:    circulationCheckpointSource,
+		Finished:  true,
+		StartedAt: startedAt,
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "source"}},
+		DoUpdates: clause.AssignmentColumns([]string{"finished", "started_at", "updated_at"}),
+	}).Create(&checkpoint).Error
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"library/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxRepository 反馈发件箱仓储接口
+type OutboxRepository interface {
+	Enqueue(entries []*model.FeedbackOutbox) error
+	ClaimDue(limit int, lease time.Duration) ([]*model.FeedbackOutbox, error)
+	Delete(ids []uint) error
+	MarkFailed(entry *model.FeedbackOutbox) error
+	CountByStatus() (map[model.OutboxStatus]int64, error)
+	SyncItemIDs() (int64, error)
+}
+
+// PostgresOutboxRepository PostgreSQL实现
+type PostgresOutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &PostgresOutboxRepository{db: db}
+}
+
+// Enqueue 写入发件箱，同一行为已进入死信时重置为待投递（用于重放死信），等待投递或投递中的记录保持不变
+func (r *PostgresOutboxRepository) Enqueue(entries []*model.FeedbackOutbox) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return enqueueOutbox(r.db, entries)
+}
+
+// enqueueOutbox 在给定连接（可为事务）上写入发件箱
+func enqueueOutbox(db *gorm.DB, entries []*model.FeedbackOutbox) error {
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "behavior_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"status":          model.OutboxStatusPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+			"last_error":      "",
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Eq{Column: clause.Column{Table: model.FeedbackOutbox{}.TableName(), Name: "status"}, Value: model.OutboxStatusDead},
+		}},
+	}).CreateInBatches(entries, 500).Error
+}
+
+// ClaimDue 领取到期的待投递记录，并将其下次投递时间顺延 lease，避免多实例重复投递
+func (r *PostgresOutboxRepository) ClaimDue(limit int, lease time.Duration) ([]*model.FeedbackOutbox, error) {
+	var entries []*model.FeedbackOutbox
+	now := time.Now()
+	err := r.db.Raw(`
+		UPDATE feedback_outbox SET next_attempt_at = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM feedback_outbox
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		now.Add(lease), now, model.OutboxStatusPending, now, limit,
+	).Scan(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Delete 删除已投递成功的记录
+func (r *PostgresOutboxRepository) Delete(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Where("id IN ?", ids).Delete(&model.FeedbackOutbox{}).Error
+}
+
+// MarkFailed 记录投递失败，更新重试次数、下次投递时间和状态
+func (r *PostgresOutboxRepository) MarkFailed(entry *model.FeedbackOutbox) error {
+	return r.db.Model(&model.FeedbackOutbox{}).
+		Where("id = ?", entry.ID).
+		Updates(map[string]interface{}{
+			"status":          entry.Status,
+			"attempts":        entry.Attempts,
+			"next_attempt_at": entry.NextAttemptAt,
+			"last_error":      entry.LastError,
+		}).Error
+}
+
+// CountByStatus 统计各状态的记录数
+func (r *PostgresOutboxRepository) CountByStatus() (map[model.OutboxStatus]int64, error) {
+	var rows []struct {
+		Status model.OutboxStatus
+		Count  int64
+	}
+	err := r.db.Model(&model.FeedbackOutbox{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[model.OutboxStatus]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// SyncItemIDs 将待投递记录的物品ID同步为对应行为的图书编号
+func (r *PostgresOutboxRepository) SyncItemIDs() (int64, error) {
+	result := r.db.Exec(`
+		UPDATE feedback_outbox o SET item_id = ub.book_id
+		FROM user_behaviors ub
+		WHERE o.behavior_id = ub.id AND ub.book_id <> '' AND o.item_id <> ub.book_id`)
+	return result.RowsAffected, result.Error
+}
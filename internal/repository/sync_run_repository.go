@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"library/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SyncRunRepository 同步任务执行记录仓储接口
+type SyncRunRepository interface {
+	Create(run *model.SyncRun) error
+	Save(run *model.SyncRun) error
+	List(source string, limit int) ([]*model.SyncRun, error)
+	FailRunning(source, reason string) (int64, error)
+}
+
+// PostgresSyncRunRepository PostgreSQL实现
+type PostgresSyncRunRepository struct {
+	db *gorm.DB
+}
+
+func NewSyncRunRepository(db *gorm.DB) SyncRunRepository {
+	return &PostgresSyncRunRepository{db: db}
+}
+
+// Create 保存新的执行记录
+func (r *PostgresSyncRunRepository) Create(run *model.SyncRun) error {
+	return r.db.Create(run).Error
+}
+
+// Save 更新执行记录的状态和统计
+func (r *PostgresSyncRunRepository) Save(run *model.SyncRun) error {
+	return r.db.Save(run).Error
+}
+
+// List 按开始时间倒序获取执行记录，source 为空时返回全部来源
+func (r *PostgresSyncRunRepository) List(source string, limit int) ([]*model.SyncRun, error) {
+	query := r.db.Order("started_at DESC").Limit(limit)
+	if source != "" {
+		query = query.Where("source = ?", source)
+	}
+	var runs []*model.SyncRun
+	if err := query.Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// FailRunning 将仍处于执行中的记录标记为失败，用于进程异常退出后重启时清理
+func (r *PostgresSyncRunRepository) FailRunning(source, reason string) (int64, error) {
+	result := r.db.Model(&model.SyncRun{}).
+		Where("source = ? AND status = ?", source, model.SyncRunRunning).
+		Updates(map[string]interface{}{
+			"status":      model.SyncRunFailed,
+			"error":       reason,
+			"finished_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
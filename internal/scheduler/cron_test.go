@@ -0,0 +1,98 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func at(value string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", value, time.UTC)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestScheduleNext(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		from string
+		want string
+	}{
+		// 步长
+		{"每15分钟", "*/15 * * * *", "2026-10-17 10:07", "2026-10-17 10:15"},
+		{"整点不含当前时间", "*/15 * * * *", "2026-10-17 10:15", "2026-10-17 10:30"},
+		{"起点加步长", "5/20 * * * *", "2026-10-17 10:26", "2026-10-17 10:45"},
+		{"范围内步长", "10-30/10 * * * *", "2026-10-17 10:25", "2026-10-17 10:30"},
+		{"范围内步长跨小时", "10-30/10 * * * *", "2026-10-17 10:31", "2026-10-17 11:10"},
+
+		// 范围与列表
+		{"小时范围内", "0 9-17 * * *", "2026-10-17 12:30", "2026-10-17 13:00"},
+		{"小时范围跨天", "0 9-17 * * *", "2026-10-17 17:30", "2026-10-18 09:00"},
+		{"分钟列表", "0,30 * * * *", "2026-10-17 10:10", "2026-10-17 10:30"},
+		{"列表与范围组合", "0 1,3-4 * * *", "2026-10-17 01:00", "2026-10-17 03:00"},
+
+		// 日与星期
+		{"只限定星期", "0 9 * * 1-5", "2026-10-17 10:00", "2026-10-19 09:00"},
+		{"只限定日", "0 0 15 * *", "2026-10-17 10:00", "2026-11-15 00:00"},
+		{"日与星期取并集-星期先到", "0 0 1 * 5", "2026-10-24 00:00", "2026-10-30 00:00"},
+		{"日与星期取并集-日先到", "0 0 1 * 5", "2026-10-30 00:00", "2026-11-01 00:00"},
+		{"星期7表示星期日", "0 0 * * 7", "2026-10-17 10:00", "2026-10-18 00:00"},
+		{"weekly简写", "@weekly", "2026-10-17 10:00", "2026-10-18 00:00"},
+
+		// 月与年
+		{"跨月", "0 0 1 * *", "2026-10-17 10:00", "2026-11-01 00:00"},
+		{"跨年", "0 0 1 1 *", "2026-10-17 10:00", "2027-01-01 00:00"},
+		{"年末最后一分钟", "59 23 31 12 *", "2026-12-31 23:59", "2027-12-31 23:59"},
+		{"跳过没有31日的月份", "0 0 31 * *", "2026-11-01 00:00", "2026-12-31 00:00"},
+		{"闰年2月29日", "0 0 29 2 *", "2026-03-01 00:00", "2028-02-29 00:00"},
+		{"daily简写跨天", "@daily", "2026-10-17 23:59", "2026-10-18 00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse(%q) 返回错误: %v", tt.spec, err)
+			}
+			got := schedule.Next(at(tt.from))
+			if want := at(tt.want); !got.Equal(want) {
+				t.Errorf("Next(%s) = %s，期望 %s", tt.from, got.Format("2006-01-02 15:04"), tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleNextNoMatch(t *testing.T) {
+	schedule, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("Parse 返回错误: %v", err)
+	}
+	if got := schedule.Next(at("2026-10-17 10:00")); !got.IsZero() {
+		t.Errorf("2月30日不存在，期望零值，实际 %s", got)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	specs := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-b * * * *",
+		"@yearly",
+	}
+	for _, spec := range specs {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) 期望返回错误", spec)
+		}
+	}
+}
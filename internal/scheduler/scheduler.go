@@ -0,0 +1,81 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// job 定时任务
+type job struct {
+	name     string
+	schedule *Schedule
+	run      func(ctx context.Context)
+}
+
+// Scheduler 按 cron 表达式定时执行任务
+// 每个任务在独立的协程中串行执行，上一次未结束时不会再次触发；ctx 取消后停止调度并等待执行中的任务返回
+type Scheduler struct {
+	jobs []job
+	wg   sync.WaitGroup
+}
+
+// New 创建调度器
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Add 注册任务，需在 Start 之前调用
+func (s *Scheduler) Add(name string, schedule *Schedule, run func(ctx context.Context)) {
+	s.jobs = append(s.jobs, job{name: name, schedule: schedule, run: run})
+}
+
+// Start 启动全部任务，ctx 取消时停止
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go func(j job) {
+			defer s.wg.Done()
+			s.loop(ctx, j)
+		}(j)
+	}
+}
+
+// Wait 等待全部任务协程退出，ctx 到期时放弃等待
+func (s *Scheduler) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loop 按计划等待并执行单个任务
+func (s *Scheduler) loop(ctx context.Context, j job) {
+	for {
+		next := j.schedule.Next(time.Now())
+		if next.IsZero() {
+			log.Printf("定时任务 %s 没有下一次执行时间，停止调度", j.name)
+			return
+		}
+		log.Printf("定时任务 %s 下次执行时间: %s", j.name, next.Format(time.RFC3339))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		j.run(ctx)
+	}
+}
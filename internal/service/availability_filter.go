@@ -0,0 +1,139 @@
+package service
+
+import (
+	"fmt"
+	"library/config"
+	"library/internal/gorse"
+	"library/internal/model"
+)
+
+// AvailabilityFilter 按图书状态过滤推荐结果
+// 处于剔除状态的图书不会出现在结果中，处于降权状态的图书排在可借图书之后
+type AvailabilityFilter struct {
+	exclude         map[model.BookStatus]bool
+	downrank        map[model.BookStatus]bool
+	overfetchFactor int
+	maxFetchRounds  int
+}
+
+// NewAvailabilityFilter 根据配置创建过滤器，配置中包含无效状态时返回错误
+func NewAvailabilityFilter(cfg config.RecommendConfig) (*AvailabilityFilter, error) {
+	exclude, err := parseStatuses(cfg.ExcludeStatuses)
+	if err != nil {
+		return nil, err
+	}
+	downrank, err := parseStatuses(cfg.DownrankStatuses)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AvailabilityFilter{
+		exclude:         exclude,
+		downrank:        downrank,
+		overfetchFactor: cfg.OverfetchFactor,
+		maxFetchRounds:  cfg.MaxFetchRounds,
+	}, nil
+}
+
+// parseStatuses 解析并校验状态列表
+func parseStatuses(values []string) (map[model.BookStatus]bool, error) {
+	statuses := make(map[model.BookStatus]bool, len(values))
+	for _, value := range values {
+		status := model.BookStatus(value)
+		if !status.IsValid() {
+			return nil, fmt.Errorf("无效的图书状态: %s", value)
+		}
+		statuses[status] = true
+	}
+	return statuses, nil
+}
+
+// bookStatus 返回图书的有效状态，未设置状态视为可借
+func bookStatus(book *model.BookInfo) model.BookStatus {
+	if book.Status == "" {
+		return model.BookStatusAvailable
+	}
+	return book.Status
+}
+
+// Excluded 判断图书是否应从推荐结果中剔除
+func (f *AvailabilityFilter) Excluded(book *model.BookInfo) bool {
+	return f.exclude[bookStatus(book)]
+}
+
+// Downranked 判断图书是否应排到可借图书之后
+func (f *AvailabilityFilter) Downranked(book *model.BookInfo) bool {
+	return f.downrank[bookStatus(book)]
+}
+
+// fetchFunc 从推荐源分页获取候选图书编号，同时返回实际使用的推荐源
+type fetchFunc func(n, offset int) ([]gorse.Score, model.RecommendSource, error)
+
+// collect 从推荐源分页获取候选并按状态过滤，不足 limit 时继续补取，直到达到最大轮数或候选耗尽
+func (s *BookService) collect(fetch fetchFunc, limit int) ([]*model.RecommendedBook, error) {
+	batch := limit * s.filter.overfetchFactor
+	seen := make(map[string]bool)
+	var available, downranked []*model.RecommendedBook
+	var firstSource model.RecommendSource
+
+	offset := 0
+	for round := 0; round < s.filter.maxFetchRounds && len(available) < limit; round++ {
+		scores, source, err := fetch(batch, offset)
+		if err != nil {
+			if round == 0 {
+				return nil, err
+			}
+			break // 补取失败时返回已有结果
+		}
+		if round == 0 {
+			firstSource = source
+		} else if source != firstSource {
+			break // 补取时推荐源发生切换，两个推荐源的分页位置不一致，返回已有结果
+		}
+		offset += len(scores)
+
+		ids := make([]string, 0, len(scores))
+		scoreByID := make(map[string]float64, len(scores))
+		for _, score := range scores {
+			if _, ok := scoreByID[score.Id]; !ok {
+				scoreByID[score.Id] = score.Score
+				ids = append(ids, score.Id)
+			}
+		}
+
+		books, err := s.getBooksByIDs(ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, book := range books {
+			if seen[book.BookID] || s.filter.Excluded(book) {
+				continue
+			}
+			seen[book.BookID] = true
+
+			recommended := &model.RecommendedBook{
+				BookInfo:  book,
+				Score:     scoreByID[book.BookID],
+				Available: bookStatus(book) == model.BookStatusAvailable,
+				Source:    source,
+			}
+			if s.filter.Downranked(book) {
+				downranked = append(downranked, recommended)
+			} else {
+				available = append(available, recommended)
+			}
+		}
+
+		if len(scores) < batch {
+			break // 推荐源已无更多候选
+		}
+	}
+
+	// 按推荐源顺序排名，降权图书排在可借图书之后
+	result := append(available, downranked...)
+	result = result[:minInt(len(result), limit)]
+	for i, book := range result {
+		book.Rank = i + 1
+	}
+	return result, nil
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"library/config"
+	"library/internal/gorse"
+	"library/internal/model"
+	"library/internal/repository"
+	"reflect"
+	"testing"
+)
+
+// stubBookRepo 只实现按图书编号查询的图书仓储，缺少的编号视为已删除
+type stubBookRepo struct {
+	repository.BookRepository
+	books map[string]*model.BookInfo
+}
+
+func (r *stubBookRepo) FindByBookIDs(bookIDs []string) ([]*model.BookInfo, error) {
+	books := make([]*model.BookInfo, 0, len(bookIDs))
+	for _, id := range bookIDs {
+		if book, ok := r.books[id]; ok {
+			books = append(books, book)
+		}
+	}
+	return books, nil
+}
+
+func newCollectService(t *testing.T, books map[string]*model.BookInfo) *BookService {
+	filter, err := NewAvailabilityFilter(config.RecommendConfig{
+		ExcludeStatuses:  []string{string(model.BookStatusLost)},
+		DownrankStatuses: []string{string(model.BookStatusBorrowed)},
+		OverfetchFactor:  2,
+		MaxFetchRounds:   3,
+	})
+	if err != nil {
+		t.Fatalf("创建过滤器失败: %v", err)
+	}
+	return &BookService{bookRepo: &stubBookRepo{books: books}, filter: filter}
+}
+
+// listFetch 按固定候选列表分页，sources 依次指定每一轮返回的推荐源
+func listFetch(ids []string, sources ...model.RecommendSource) fetchFunc {
+	round := 0
+	return func(n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+		source := sources[minInt(round, len(sources)-1)]
+		round++
+		scores := make([]gorse.Score, 0, n)
+		for i := offset; i < len(ids) && i < offset+n; i++ {
+			scores = append(scores, gorse.Score{Id: ids[i], Score: float64(len(ids) - i)})
+		}
+		return scores, source, nil
+	}
+}
+
+func TestCollect(t *testing.T) {
+	books := make(map[string]*model.BookInfo)
+	status := map[string]model.BookStatus{"b1": model.BookStatusBorrowed, "b3": model.BookStatusLost, "b4": model.BookStatusLost, "b5": model.BookStatusLost}
+	for i := 0; i < 12; i++ {
+		id := fmt.Sprintf("b%d", i)
+		if i == 6 {
+			continue // 已删除
+		}
+		books[id] = &model.BookInfo{BookID: id, Status: status[id]}
+	}
+	all := make([]string, 0, 12)
+	for i := 0; i < 12; i++ {
+		all = append(all, fmt.Sprintf("b%d", i))
+	}
+
+	tests := []struct {
+		name  string
+		ids   []string
+		limit int
+		want  []string
+	}{
+		{"剔除丢失和已删除，借出的排在最后", all[:6], 3, []string{"b0", "b2", "b1"}},
+		{"不足时补取", all, 4, []string{"b0", "b2", "b7", "b8"}},
+		{"候选耗尽时返回已有结果", all[:4], 4, []string{"b0", "b2", "b1"}},
+		{"重复候选只保留一次", []string{"b0", "b0", "b2", "b2"}, 4, []string{"b0", "b2"}},
+		{"没有候选", nil, 4, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newCollectService(t, books)
+			result, err := s.collect(listFetch(tt.ids, model.RecommendSourceGorse), tt.limit)
+			if err != nil {
+				t.Fatalf("collect 返回错误: %v", err)
+			}
+			got := make([]string, 0, len(result))
+			for i, book := range result {
+				got = append(got, book.BookID)
+				if book.Rank != i+1 || book.Source != model.RecommendSourceGorse {
+					t.Errorf("%s 的排名 = %d、推荐源 = %s，期望 %d、gorse", book.BookID, book.Rank, book.Source, i+1)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("collect = %v，期望 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectStopsOnSourceSwitch(t *testing.T) {
+	books := make(map[string]*model.BookInfo)
+	ids := make([]string, 0, 10)
+	for i := 0; i < 10; i++ {
+		id := fmt.Sprintf("b%d", i)
+		ids = append(ids, id)
+		books[id] = &model.BookInfo{BookID: id, Status: model.BookStatusLost}
+	}
+	books["b0"].Status = model.BookStatusAvailable
+
+	s := newCollectService(t, books)
+	result, err := s.collect(listFetch(ids, model.RecommendSourceGorse, model.RecommendSourceLocal), 3)
+	if err != nil {
+		t.Fatalf("collect 返回错误: %v", err)
+	}
+	if len(result) != 1 || result[0].BookID != "b0" {
+		t.Errorf("推荐源切换后应停止补取，实际结果 %v", result)
+	}
+}
+
+func TestCollectErrors(t *testing.T) {
+	s := newCollectService(t, map[string]*model.BookInfo{"b0": {BookID: "b0"}, "b1": {BookID: "b1", Status: model.BookStatusLost}})
+	failing := errors.New("gorse down")
+
+	// 第一轮失败时返回错误
+	_, err := s.collect(func(n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+		return nil, model.RecommendSourceGorse, failing
+	}, 2)
+	if !errors.Is(err, failing) {
+		t.Errorf("第一轮失败时返回 %v，期望 %v", err, failing)
+	}
+
+	// 补取失败时返回已有结果
+	round := 0
+	result, err := s.collect(func(n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+		round++
+		if round > 1 {
+			return nil, model.RecommendSourceGorse, failing
+		}
+		return []gorse.Score{{Id: "b0"}, {Id: "b1"}, {Id: "b2"}, {Id: "b3"}}, model.RecommendSourceGorse, nil
+	}, 2)
+	if err != nil || len(result) != 1 {
+		t.Errorf("补取失败时返回 %d 条结果、错误 %v，期望返回已有的 1 条", len(result), err)
+	}
+}
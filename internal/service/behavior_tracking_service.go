@@ -0,0 +1,263 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"library/internal/model"
+	"library/internal/repository"
+	"math"
+	"time"
+)
+
+const (
+	// interestHistoryLimit 兴趣分析时读取的最大行为条数
+	interestHistoryLimit = 1000
+	// interestHalfLife 兴趣权重的时间衰减半衰期
+	interestHalfLife = 30 * 24 * time.Hour
+)
+
+// behaviorWeights 各类行为在兴趣分析中的基础权重
+var behaviorWeights = map[model.BehaviorType]float64{
+	model.BehaviorView:     1.0,
+	model.BehaviorClick:    2.0,
+	model.BehaviorStayTime: 2.0,
+	model.BehaviorRead:     4.0,
+}
+
+// BehaviorTrackingService 基于 user_behaviors 表的行为追踪服务
+type BehaviorTrackingService struct {
+	behaviorRepo repository.BehaviorRepository
+	bookRepo     repository.BookRepository
+}
+
+// NewBehaviorTrackingService 创建新的 BehaviorTrackingService 实例
+func NewBehaviorTrackingService(behaviorRepo repository.BehaviorRepository, bookRepo repository.BookRepository) *BehaviorTrackingService {
+	return &BehaviorTrackingService{
+		behaviorRepo: behaviorRepo,
+		bookRepo:     bookRepo,
+	}
+}
+
+// TrackUserBehavior 记录单条用户行为，itemID 为图书编号(book_id)
+// metadata 中的 element、position、scroll_depth、stay_time 会写入对应列，其余写入 Extra
+func (s *BehaviorTrackingService) TrackUserBehavior(userID, itemID, behaviorType string, metadata map[string]interface{}) error {
+	behavior := model.UserBehavior{
+		UserID: userID,
+		BookID: itemID,
+		Type:   model.BehaviorType(behaviorType),
+	}
+
+	extra := make(map[string]interface{})
+	for k, v := range metadata {
+		switch k {
+		case "element":
+			if element, ok := v.(string); ok {
+				behavior.Element = element
+				continue
+			}
+		case "position":
+			if err := decodeMetadata(v, &behavior.Position); err == nil {
+				continue
+			}
+		case "scroll_depth":
+			if depth, ok := toInt(v); ok {
+				behavior.ScrollDepth = depth
+				continue
+			}
+		case "stay_time":
+			if stayTime, ok := toInt(v); ok {
+				behavior.StayTime = stayTime
+				continue
+			}
+		}
+		extra[k] = v
+	}
+
+	if len(extra) > 0 {
+		data, err := json.Marshal(extra)
+		if err != nil {
+			return fmt.Errorf("序列化额外信息失败: %v", err)
+		}
+		behavior.Extra = string(data)
+	}
+
+	return s.BatchTrackUserBehavior([]model.UserBehavior{behavior})
+}
+
+// BatchTrackUserBehavior 批量记录用户行为
+// 记录与发件箱同事务入库，由 FeedbackDispatcher 异步投递到Gorse
+func (s *BehaviorTrackingService) BatchTrackUserBehavior(behaviors []model.UserBehavior) error {
+	if len(behaviors) == 0 {
+		return nil
+	}
+
+	bookIDs := make([]string, 0, len(behaviors))
+	for i := range behaviors {
+		if err := validateBehavior(&behaviors[i]); err != nil {
+			return fmt.Errorf("第 %d 条行为参数验证失败: %w", i+1, err)
+		}
+		bookIDs = append(bookIDs, behaviors[i].BookID)
+	}
+
+	books, err := s.bookRepo.FindByBookIDs(bookIDs)
+	if err != nil {
+		return fmt.Errorf("获取图书信息失败: %v", err)
+	}
+	titles := make(map[string]string, len(books))
+	for _, book := range books {
+		titles[book.BookID] = book.Title
+	}
+
+	now := time.Now()
+	records := make([]*model.UserBehavior, 0, len(behaviors))
+	for i := range behaviors {
+		behavior := behaviors[i]
+		title, ok := titles[behavior.BookID]
+		if !ok {
+			return fmt.Errorf("图书不存在: %s", behavior.BookID)
+		}
+		behavior.BookTitle = title
+		if behavior.Timestamp.IsZero() {
+			behavior.Timestamp = now
+		}
+		if behavior.FeedbackType == "" {
+			behavior.FeedbackType = string(behavior.Type)
+			if behavior.Type == model.BehaviorStayTime {
+				behavior.FeedbackType = stayTimeFeedbackType(behavior.StayTime)
+			}
+		}
+		behavior.ForwardedAt = nil
+		records = append(records, &behavior)
+	}
+
+	if err := s.behaviorRepo.CreateWithOutbox(records); err != nil {
+		return fmt.Errorf("保存用户行为失败: %v", err)
+	}
+	return nil
+}
+
+// GetUserBehaviorHistory 获取用户最近的行为历史
+func (s *BehaviorTrackingService) GetUserBehaviorHistory(userID string, limit int) ([]*model.UserBehavior, error) {
+	behaviors, err := s.behaviorRepo.ListByUser(userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("获取用户行为历史失败: %v", err)
+	}
+	return behaviors, nil
+}
+
+// AnalyzeUserInterests 分析用户兴趣
+// 返回的键形如 "class:T"、"subclass:TP"、"author:xxx"、"publisher:xxx"，值为归一化到 [0,1] 的兴趣权重
+func (s *BehaviorTrackingService) AnalyzeUserInterests(userID string) (map[string]float64, error) {
+	behaviors, err := s.behaviorRepo.ListByUser(userID, interestHistoryLimit)
+	if err != nil {
+		return nil, fmt.Errorf("获取用户行为历史失败: %v", err)
+	}
+	if len(behaviors) == 0 {
+		return map[string]float64{}, nil
+	}
+
+	bookIDs := make([]string, 0, len(behaviors))
+	for _, behavior := range behaviors {
+		if behavior.BookID != "" {
+			bookIDs = append(bookIDs, behavior.BookID)
+		}
+	}
+	books, err := s.bookRepo.FindByBookIDs(bookIDs)
+	if err != nil {
+		return nil, fmt.Errorf("获取图书信息失败: %v", err)
+	}
+	bookMap := make(map[string]*model.BookInfo, len(books))
+	for _, book := range books {
+		bookMap[book.BookID] = book
+	}
+
+	now := time.Now()
+	interests := make(map[string]float64)
+	for _, behavior := range behaviors {
+		book, ok := bookMap[behavior.BookID]
+		if !ok {
+			continue
+		}
+
+		weight := behaviorWeight(behavior) * timeDecay(now.Sub(behavior.Timestamp))
+		if class := model.CLCTopClass(book.ClassificationNumber); class != "" {
+			interests["class:"+class] += weight
+		}
+		if subclass := model.CLCSubClass(book.ClassificationNumber); subclass != "" {
+			interests["subclass:"+subclass] += weight
+		}
+		if book.PrimaryAuthor != "" {
+			interests["author:"+book.PrimaryAuthor] += weight
+		}
+		if book.Publisher != "" {
+			interests["publisher:"+book.Publisher] += weight
+		}
+	}
+
+	// 归一化到 [0,1]
+	maxWeight := 0.0
+	for _, w := range interests {
+		maxWeight = math.Max(maxWeight, w)
+	}
+	if maxWeight > 0 {
+		for k, w := range interests {
+			interests[k] = w / maxWeight
+		}
+	}
+
+	return interests, nil
+}
+
+// validateBehavior 验证行为记录的必填字段
+func validateBehavior(behavior *model.UserBehavior) error {
+	if behavior.UserID == "" {
+		return fmt.Errorf("user_id is required")
+	}
+	if behavior.BookID == "" {
+		return fmt.Errorf("book_id is required")
+	}
+	if _, ok := behaviorWeights[behavior.Type]; !ok {
+		return fmt.Errorf("unsupported behavior type: %s", behavior.Type)
+	}
+	return nil
+}
+
+// behaviorWeight 计算单条行为的权重，停留类行为按时长加权
+func behaviorWeight(behavior *model.UserBehavior) float64 {
+	weight := behaviorWeights[behavior.Type]
+	if behavior.StayTime > 0 {
+		weight *= 1 + math.Min(float64(behavior.StayTime)/300, 2)
+	}
+	return weight
+}
+
+// timeDecay 按半衰期计算时间衰减系数
+func timeDecay(age time.Duration) float64 {
+	if age <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, float64(age)/float64(interestHalfLife))
+}
+
+// decodeMetadata 将任意元数据值解码到目标结构
+func decodeMetadata(v interface{}, target interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// toInt 将元数据中的数值转换为整数
+func toInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"library/internal/model"
+	"library/internal/repository"
+	"log"
+	"time"
+)
+
+var (
+	// ErrInvalidBook 图书字段校验失败
+	ErrInvalidBook = errors.New("图书信息无效")
+	// ErrBookExists 图书编号或条形码已被其他记录占用
+	ErrBookExists = errors.New("图书编号或条形码已存在")
+	// ErrBookModified 图书在读取之后已被他人修改
+	ErrBookModified = errors.New("图书已被修改，请重新获取后再提交")
+	// ErrGorsePublish 数据库已更新但同步到Gorse失败，下次馆藏同步或重新保存时会再次写入
+	ErrGorsePublish = errors.New("同步到推荐系统失败")
+)
+
+// BookAdminService 馆员维护馆藏：新增本地馆藏、修正编目信息、修改状态和删除，变更同步到Gorse物品并清空推荐缓存
+type BookAdminService struct {
+	bookRepo  repository.BookRepository
+	publisher *CatalogPublisher
+	cache     RecommendationCacheInterface
+}
+
+// NewBookAdminService 创建新的 BookAdminService 实例
+func NewBookAdminService(bookRepo repository.BookRepository, publisher *CatalogPublisher, cache RecommendationCacheInterface) *BookAdminService {
+	return &BookAdminService{
+		bookRepo:  bookRepo,
+		publisher: publisher,
+		cache:     cache,
+	}
+}
+
+// GetBook 按图书编号获取图书，返回的 updated_at 用于之后的修改和删除
+func (s *BookAdminService) GetBook(bookID string) (*model.BookInfo, error) {
+	book, err := s.bookRepo.GetBookByBookID(bookID)
+	if err != nil {
+		return nil, lookupError(bookID, err)
+	}
+	return book, nil
+}
+
+// CreateBook 新增本地馆藏，主键在数据平台ID范围之外分配；本地馆藏不参与上游删除检测
+func (s *BookAdminService) CreateBook(req *model.BookCreateRequest) (*model.BookInfo, error) {
+	book, err := req.ToBookInfo()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBook, err)
+	}
+	if err := s.checkConflict(book); err != nil {
+		return nil, err
+	}
+
+	if book.ID, err = s.bookRepo.NextLocalID(); err != nil {
+		return nil, fmt.Errorf("分配图书主键失败: %v", err)
+	}
+	if err := s.bookRepo.CreateBookInfo(book); err != nil {
+		// 并发新增同一图书编号或条形码时，后写入的一方违反唯一约束
+		if conflictErr := s.checkConflict(book); conflictErr != nil {
+			return nil, conflictErr
+		}
+		return nil, fmt.Errorf("新增图书失败: %v", err)
+	}
+	log.Printf("馆员新增图书 %s（%s）", book.BookID, book.Title)
+	defer s.cache.InvalidateRecommendationCache()
+	return s.reloadAndPublish(book.BookID)
+}
+
+// UpdateBook 修改图书，req.UpdatedAt 与当前记录不一致时返回 ErrBookModified
+func (s *BookAdminService) UpdateBook(bookID string, req *model.BookUpdateRequest) (*model.BookInfo, error) {
+	book, err := s.bookRepo.GetBookByBookID(bookID)
+	if err != nil {
+		return nil, lookupError(bookID, err)
+	}
+	if !book.UpdatedAt.Equal(req.UpdatedAt) {
+		return nil, ErrBookModified
+	}
+
+	if err := req.Apply(book); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBook, err)
+	}
+	if err := s.checkConflict(book); err != nil {
+		return nil, err
+	}
+
+	book.UpdatedAt = time.Now()
+	updated, err := s.bookRepo.UpdateBookIfUnmodified(book, req.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("更新图书失败: %v", err)
+	}
+	if !updated {
+		return nil, ErrBookModified
+	}
+	log.Printf("馆员修改图书 %s", bookID)
+	defer s.cache.InvalidateRecommendationCache()
+	return s.reloadAndPublish(bookID)
+}
+
+// DeleteBook 软删除图书并在Gorse中隐藏，保留历史反馈；updatedAt 不为 nil 时进行并发检查
+func (s *BookAdminService) DeleteBook(bookID string, updatedAt *time.Time) error {
+	deleted, err := s.bookRepo.DeleteBookIfUnmodified(bookID, updatedAt)
+	if err != nil {
+		return fmt.Errorf("删除图书失败: %v", err)
+	}
+	if !deleted {
+		// 区分记录不存在和已被修改
+		if _, err := s.bookRepo.GetBookByBookID(bookID); err != nil {
+			return lookupError(bookID, err)
+		}
+		return ErrBookModified
+	}
+	log.Printf("馆员删除图书 %s", bookID)
+	defer s.cache.InvalidateRecommendationCache()
+
+	if err := s.publisher.HideBooks([]string{bookID}); err != nil {
+		return fmt.Errorf("%w: %v", ErrGorsePublish, err)
+	}
+	return nil
+}
+
+// checkConflict 检查图书编号和条形码是否被其他记录占用
+func (s *BookAdminService) checkConflict(book *model.BookInfo) error {
+	conflicts, err := s.bookRepo.FindConflicting(book.BookID, book.BookBarcode, book.ID)
+	if err != nil {
+		return fmt.Errorf("检查图书编号和条形码失败: %v", err)
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%w: 与图书 %s 冲突", ErrBookExists, conflicts[0].BookID)
+	}
+	return nil
+}
+
+// reloadAndPublish 重新读取写入后的记录（获得数据库精度的 updated_at），并将其写入Gorse
+// 写入Gorse失败时仍返回图书，错误为 ErrGorsePublish
+func (s *BookAdminService) reloadAndPublish(bookID string) (*model.BookInfo, error) {
+	book, err := s.bookRepo.GetBookByBookID(bookID)
+	if err != nil {
+		return nil, lookupError(bookID, err)
+	}
+
+	if err := s.publisher.PublishBooks([]*model.BookInfo{book}); err != nil {
+		log.Printf("图书 %s 同步到Gorse失败: %v", bookID, err)
+		return book, fmt.Errorf("%w: %v", ErrGorsePublish, err)
+	}
+	return book, nil
+}
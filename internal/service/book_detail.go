@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"library/internal/model"
+	"log"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+const (
+	// detailSimilarLimit 图书详情中附带的相似图书数
+	detailSimilarLimit = 10
+	// defaultBatchPageSize 批量查询默认每页条数
+	defaultBatchPageSize = 50
+	// maxBatchPageSize 批量查询每页最大条数
+	maxBatchPageSize = 200
+)
+
+// ErrBookNotFound 图书不存在
+var ErrBookNotFound = errors.New("图书不存在")
+
+// GetBookDetail 按图书编号获取图书详情，找不到且参数为数字时按主键查找
+func (s *BookService) GetBookDetail(ctx context.Context, id string) (*model.BookDetail, error) {
+	book, err := s.bookRepo.GetBookByBookID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if _, parseErr := decimal.NewFromString(id); parseErr == nil {
+			book, err = s.bookRepo.GetBookInfoByID(id)
+		}
+	}
+	if err != nil {
+		return nil, lookupError(id, err)
+	}
+	return s.enrichDetail(ctx, book), nil
+}
+
+// GetBookDetailByBarcode 按条形码获取图书详情
+func (s *BookService) GetBookDetailByBarcode(ctx context.Context, barcode string) (*model.BookDetail, error) {
+	book, err := s.bookRepo.GetBookInfoByBarcode(barcode)
+	if err != nil {
+		return nil, lookupError(barcode, err)
+	}
+	return s.enrichDetail(ctx, book), nil
+}
+
+// BatchGetBooks 按图书编号分页批量获取图书
+func (s *BookService) BatchGetBooks(req *model.BatchBookRequest) (*model.BatchBookResponse, error) {
+	if req.PageNumber <= 0 {
+		req.PageNumber = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = defaultBatchPageSize
+	}
+	if req.PageSize > maxBatchPageSize {
+		req.PageSize = maxBatchPageSize
+	}
+
+	books, total, err := s.bookRepo.BatchGetBookInfo(req.PageSize, req.PageNumber, req.BookIDs)
+	if err != nil {
+		return nil, fmt.Errorf("批量获取图书失败: %v", err)
+	}
+	return &model.BatchBookResponse{
+		Total:    int(total),
+		Page:     req.PageNumber,
+		PageSize: req.PageSize,
+		Books:    books,
+		HasMore:  int64(req.PageNumber*req.PageSize) < total,
+	}, nil
+}
+
+// lookupError 区分图书不存在和查询失败
+func lookupError(key string, err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: %s", ErrBookNotFound, key)
+	}
+	return fmt.Errorf("获取图书信息失败: %v", err)
+}
+
+// enrichDetail 补充可借状态和相似图书，相似图书在 shelfTimeout 内取不到时留空，不影响详情返回
+func (s *BookService) enrichDetail(ctx context.Context, book *model.BookInfo) *model.BookDetail {
+	detail := &model.BookDetail{
+		BookInfo:       book,
+		Available:      bookStatus(book) == model.BookStatusAvailable,
+		SimilarBookIDs: []string{},
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.shelfTimeout)
+	defer cancel()
+
+	done := make(chan []*model.RecommendedBook, 1)
+	go func() {
+		similar, err := s.GetSimilarBooks(ctx, book.BookID, "", detailSimilarLimit)
+		if err != nil {
+			log.Printf("获取图书 %s 的相似图书失败: %v", book.BookID, err)
+		}
+		done <- similar
+	}()
+
+	select {
+	case similar := <-done:
+		for _, b := range similar {
+			detail.SimilarBookIDs = append(detail.SimilarBookIDs, b.BookID)
+		}
+	case <-ctx.Done():
+		log.Printf("获取图书 %s 的相似图书未完成: %v", book.BookID, ctx.Err())
+	}
+	return detail
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"fmt"
+	"library/internal/model"
+	"strings"
+)
+
+const (
+	// defaultSearchPageSize 检索默认每页条数
+	defaultSearchPageSize = 20
+	// maxSearchPageSize 检索每页最大条数
+	maxSearchPageSize = 100
+)
+
+// SearchBooks 按关键词和分面条件检索馆藏，返回分页结果和当前条件下的分面统计
+func (s *BookService) SearchBooks(query *model.BookSearchQuery) (*model.BookSearchResponse, error) {
+	query.Q = strings.TrimSpace(query.Q)
+	query.Classification = strings.ToUpper(strings.TrimSpace(query.Classification))
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = defaultSearchPageSize
+	}
+	if query.PageSize > maxSearchPageSize {
+		query.PageSize = maxSearchPageSize
+	}
+
+	books, total, err := s.bookRepo.SearchBooks(query)
+	if err != nil {
+		return nil, fmt.Errorf("检索图书失败: %v", err)
+	}
+	facets, err := s.bookRepo.SearchFacets(query)
+	if err != nil {
+		return nil, fmt.Errorf("统计检索分面失败: %v", err)
+	}
+
+	return &model.BookSearchResponse{
+		BatchBookResponse: model.BatchBookResponse{
+			Total:    int(total),
+			Page:     query.Page,
+			PageSize: query.PageSize,
+			Books:    books,
+			HasMore:  int64(query.Page*query.PageSize) < total,
+		},
+		Query:  query.Q,
+		Facets: facets,
+	}, nil
+}
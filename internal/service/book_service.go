@@ -1,32 +1,237 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"library/config"
 	"library/internal/gorse"
 	"library/internal/model"
 	"library/internal/repository"
+	"sort"
 	"time"
 )
 
 // BookService 处理图书相关的业务逻辑
 type BookService struct {
-	bookRepo    repository.BookRepository
-	gorseClient *gorse.Client
+	bookRepo     repository.BookRepository
+	behaviorRepo repository.BehaviorRepository
+	dispatcher   *FeedbackDispatcher
+	filter       *AvailabilityFilter
+	shelfTimeout time.Duration
+	recommender  Recommender
+	cache        *resultCache // 热门、最新、相似图书候选列表的缓存
+	cacheTTL     cacheTTLs
 }
 
-// NewBookService 创建新的 BookService 实例
-func NewBookService(bookRepo repository.BookRepository, gorseEndpoint, gorseAPIKey string) *BookService {
-	return &BookService{
-		bookRepo:    bookRepo,
-		gorseClient: gorse.NewClient(gorseEndpoint, gorseAPIKey),
+// cacheTTLs 各类推荐结果的缓存时间
+type cacheTTLs struct {
+	popular time.Duration
+	latest  time.Duration
+	similar time.Duration
+}
+
+// NewBookService 创建新的 BookService 实例，推荐配置无效时返回错误
+func NewBookService(bookRepo repository.BookRepository, behaviorRepo repository.BehaviorRepository, dispatcher *FeedbackDispatcher, recommendCfg config.RecommendConfig, recommender Recommender) (*BookService, error) {
+	filter, err := NewAvailabilityFilter(recommendCfg)
+	if err != nil {
+		return nil, err
 	}
+
+	return &BookService{
+		bookRepo:     bookRepo,
+		behaviorRepo: behaviorRepo,
+		dispatcher:   dispatcher,
+		filter:       filter,
+		shelfTimeout: recommendCfg.ShelfTimeout,
+		recommender:  recommender,
+		cache:        newResultCache(recommendCfg.CacheMaxEntries, recommender.Source()),
+		cacheTTL: cacheTTLs{
+			popular: recommendCfg.PopularCacheTTL,
+			latest:  recommendCfg.LatestCacheTTL,
+			similar: recommendCfg.SimilarCacheTTL,
+		},
+	}, nil
 }
 
 // RecordUserBehavior 统一的用户行为记录接口
 func (s *BookService) RecordUserBehavior(req *UserBehaviorRequest) error {
+	behavior, err := s.buildBehavior(req)
+	if err != nil {
+		return err
+	}
+
+	// Gorse以图书编号作为物品ID，必须关联到馆藏记录
+	book, err := s.resolveBook(req.BookID, req.BookTitle)
+	if err != nil {
+		return err
+	}
+	behavior.BookID = book.BookID
+	behavior.BookTitle = book.Title
+
+	// 行为与发件箱记录同事务入库后立即返回，由后台任务异步投递到Gorse
+	if err := s.behaviorRepo.CreateWithOutbox([]*model.UserBehavior{behavior}); err != nil {
+		return fmt.Errorf("保存用户行为失败: %v", err)
+	}
+
+	s.dispatcher.Notify()
+	return nil
+}
+
+// RecordUserBehaviors 批量记录用户行为
+// 每条请求单独验证，合法的行为统一入库并写入发件箱，由后台任务通过Gorse批量接口投递
+func (s *BookService) RecordUserBehaviors(reqs []*UserBehaviorRequest) (*BatchBehaviorResult, error) {
+	result := &BatchBehaviorResult{
+		Total:   len(reqs),
+		Results: make([]BehaviorItemResult, len(reqs)),
+	}
+
+	built := make(map[int]*model.UserBehavior, len(reqs))
+	bookIDs := make([]string, 0, len(reqs))
+	titles := make([]string, 0, len(reqs))
+	for i, req := range reqs {
+		result.Results[i].Index = i
+		if req == nil {
+			result.Results[i].Error = "行为数据为空"
+			result.Failed++
+			continue
+		}
+		behavior, err := s.buildBehavior(req)
+		if err != nil {
+			result.Results[i].Error = err.Error()
+			result.Failed++
+			continue
+		}
+		built[i] = behavior
+		if req.BookID != "" {
+			bookIDs = append(bookIDs, req.BookID)
+		} else {
+			titles = append(titles, req.BookTitle)
+		}
+	}
+
+	if len(built) == 0 {
+		return result, nil
+	}
+
+	// 批量关联馆藏记录
+	byID, byTitle, err := s.lookupBooks(bookIDs, titles)
+	if err != nil {
+		return nil, err
+	}
+
+	behaviors := make([]*model.UserBehavior, 0, len(built))
+	for i := range reqs {
+		behavior, ok := built[i]
+		if !ok {
+			continue
+		}
+		var book *model.BookInfo
+		if reqs[i].BookID != "" {
+			book = byID[reqs[i].BookID]
+		} else {
+			book = byTitle[reqs[i].BookTitle]
+		}
+		if book == nil {
+			result.Results[i].Error = "图书不存在"
+			result.Failed++
+			continue
+		}
+		behavior.BookID = book.BookID
+		behavior.BookTitle = book.Title
+		behaviors = append(behaviors, behavior)
+	}
+
+	if len(behaviors) == 0 {
+		return result, nil
+	}
+
+	if err := s.behaviorRepo.CreateWithOutbox(behaviors); err != nil {
+		return nil, fmt.Errorf("保存用户行为失败: %v", err)
+	}
+	s.dispatcher.Notify()
+
+	for i := range result.Results {
+		if result.Results[i].Error == "" {
+			result.Results[i].Success = true
+			result.Succeeded++
+		}
+	}
+
+	return result, nil
+}
+
+// ResolveBookID 根据图书编号或标题解析出图书编号，标题对应多个版本时取最早入库的记录
+func (s *BookService) ResolveBookID(bookID, title string) (string, error) {
+	book, err := s.resolveBook(bookID, title)
+	if err != nil {
+		return "", err
+	}
+	return book.BookID, nil
+}
+
+// resolveBook 根据图书编号或标题查找馆藏记录，优先使用图书编号
+func (s *BookService) resolveBook(bookID, title string) (*model.BookInfo, error) {
+	if bookID != "" {
+		book, err := s.bookRepo.GetBookByBookID(bookID)
+		if err != nil {
+			return nil, fmt.Errorf("图书不存在: %s", bookID)
+		}
+		return book, nil
+	}
+	if title != "" {
+		book, err := s.bookRepo.GetBookByTitle(title)
+		if err != nil {
+			return nil, fmt.Errorf("图书不存在: %s", title)
+		}
+		return book, nil
+	}
+	return nil, fmt.Errorf("book_id 或 title 至少提供一个")
+}
+
+// lookupBooks 批量按图书编号和标题查找馆藏记录
+func (s *BookService) lookupBooks(bookIDs, titles []string) (map[string]*model.BookInfo, map[string]*model.BookInfo, error) {
+	byID := make(map[string]*model.BookInfo, len(bookIDs))
+	if len(bookIDs) > 0 {
+		books, err := s.bookRepo.FindByBookIDs(bookIDs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("获取图书信息失败: %v", err)
+		}
+		for _, book := range books {
+			byID[book.BookID] = book
+		}
+	}
+
+	byTitle := make(map[string]*model.BookInfo, len(titles))
+	if len(titles) > 0 {
+		books, _, err := s.bookRepo.BatchGetBooksByTitles(titles)
+		if err != nil {
+			return nil, nil, fmt.Errorf("获取图书信息失败: %v", err)
+		}
+		// 同名图书取最早入库的记录，与 ResolveBookID 保持一致
+		for _, book := range books {
+			if existing, ok := byTitle[book.Title]; !ok || book.ID.LessThan(existing.ID) {
+				byTitle[book.Title] = book
+			}
+		}
+	}
+
+	return byID, byTitle, nil
+}
+
+// stayTimeFeedbackType 根据停留时间决定反馈类型
+func stayTimeFeedbackType(stayTimeSeconds int) string {
+	if stayTimeSeconds >= 30 {
+		return "read"
+	}
+	return "view"
+}
+
+// buildBehavior 验证请求并构建待持久化的行为记录
+func (s *BookService) buildBehavior(req *UserBehaviorRequest) (*model.UserBehavior, error) {
 	// 验证请求参数
 	if err := req.Validate(); err != nil {
-		return fmt.Errorf("参数验证失败: %w", err)
+		return nil, fmt.Errorf("参数验证失败: %w", err)
 	}
 
 	var extra map[string]interface{}
@@ -42,12 +247,7 @@ func (s *BookService) RecordUserBehavior(req *UserBehaviorRequest) error {
 		extra = map[string]interface{}{
 			"stay_time": *req.StayTimeSeconds,
 		}
-		// 根据停留时间决定反馈类型
-		if *req.StayTimeSeconds >= 30 {
-			feedbackType = "read"
-		} else {
-			feedbackType = "view"
-		}
+		feedbackType = stayTimeFeedbackType(*req.StayTimeSeconds)
 	case "view", "click":
 		// 这些行为类型不需要额外处理
 		extra = req.Extra
@@ -63,8 +263,37 @@ func (s *BookService) RecordUserBehavior(req *UserBehaviorRequest) error {
 		}
 	}
 
-	// 记录到Gorse推荐系统
-	return s.gorseClient.InsertFeedback(feedbackType, req.UserID, req.BookTitle, time.Now().Unix(), extra)
+	behavior := &model.UserBehavior{
+		UserID:       req.UserID,
+		BookID:       req.BookID,
+		BookTitle:    req.BookTitle,
+		Type:         model.BehaviorType(req.BehaviorType),
+		FeedbackType: feedbackType,
+		Element:      req.Element,
+		Timestamp:    time.Now(),
+	}
+
+	if req.Position != nil {
+		behavior.Position = *req.Position
+	}
+	if req.ScrollDepth != nil {
+		behavior.ScrollDepth = *req.ScrollDepth
+	}
+	if req.StayTimeSeconds != nil {
+		behavior.StayTime = *req.StayTimeSeconds
+	} else if req.ReadTimeMinutes != nil {
+		behavior.StayTime = *req.ReadTimeMinutes * 60
+	}
+
+	if extra != nil {
+		data, err := json.Marshal(extra)
+		if err != nil {
+			return nil, fmt.Errorf("序列化额外信息失败: %v", err)
+		}
+		behavior.Extra = string(data)
+	}
+
+	return behavior, nil
 }
 
 // 保留原有方法以兼容现有代码
@@ -103,53 +332,71 @@ func (s *BookService) RecordBookStayTime(userID, title string, stayTimeSeconds i
 }
 
 // GetRecommendations 获取图书推荐，包含对新用户的处理
-func (s *BookService) GetRecommendations(userID string, limit int) ([]*model.BookInfo, error) {
+func (s *BookService) GetRecommendations(ctx context.Context, userID, category string, limit int) ([]*model.RecommendedBook, error) {
 	// 先尝试获取个性化推荐
-	recommendationTitles, err := s.gorseClient.GetRecommend(userID, "", limit)
+	books, err := s.collect(func(n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+		ids, source, err := s.recommender.GetPersonalizedRecommendations(ctx, userID, category, n, offset)
+		if err != nil {
+			return nil, source, fmt.Errorf("获取推荐失败: %w", err)
+		}
+		return ids, source, nil
+	}, limit)
 	if err != nil {
-		return nil, fmt.Errorf("获取推荐失败: %v", err)
+		return nil, err
 	}
 
 	// 如果没有个性化推荐结果，使用默认推荐策略
-	if len(recommendationTitles) == 0 {
-		recommendationTitles, err = s.getDefaultRecommendations(limit)
-		if err != nil {
-			return nil, err
-		}
+	if len(books) == 0 {
+		// 首轮按热门与最新混合，后续补取时从已消耗的热门位置继续
+		popularOffset := 0
+		return s.collect(func(n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+			if offset == 0 {
+				ids, popularCount, source, err := s.getDefaultRecommendations(ctx, category, n)
+				popularOffset = popularCount
+				return ids, source, err
+			}
+			ids, source, err := s.recommender.GetPopularRecommendations(ctx, category, n, popularOffset)
+			if err != nil {
+				return nil, source, fmt.Errorf("获取热门图书失败: %w", err)
+			}
+			popularOffset += len(ids)
+			return ids, source, nil
+		}, limit)
 	}
 
-	// 根据标题获取完整的图书信息
-	return s.getBooksByTitles(recommendationTitles)
+	return books, nil
 }
 
-// getDefaultRecommendations 获取默认推荐（针对新用户）
-func (s *BookService) getDefaultRecommendations(limit int) ([]string, error) {
+// getDefaultRecommendations 获取默认推荐（针对新用户），同时返回其中热门图书的数量及热门图书的推荐源
+func (s *BookService) getDefaultRecommendations(ctx context.Context, category string, limit int) ([]gorse.Score, int, model.RecommendSource, error) {
 	// 策略1：获取热门图书（占比60%）
 	popularLimit := int(float64(limit) * 0.6)
-	popularBooks, err := s.gorseClient.GetPopular("", popularLimit)
+	popularBooks, source, err := s.recommender.GetPopularRecommendations(ctx, category, popularLimit, 0)
 	if err != nil {
-		return nil, fmt.Errorf("获取热门图书失败: %v", err)
+		return nil, 0, source, fmt.Errorf("获取热门图书失败: %w", err)
 	}
 
 	// 策略2：获取最新图书（占比40%）
 	latestLimit := limit - len(popularBooks)
-	latestBooks, err := s.gorseClient.GetLatest("", latestLimit)
+	latestBooks, _, err := s.recommender.GetLatestRecommendations(ctx, category, latestLimit, 0)
 	if err != nil {
-		latestBooks = []string{} // 如果获取最新图书失败，使用空列表
+		latestBooks = []gorse.Score{} // 如果获取最新图书失败，使用空列表
 	}
 
 	// 合并推荐结果
+	popularCount := len(popularBooks)
 	recommendations := append(popularBooks, latestBooks...)
 
 	// 如果合并后的结果仍然不足，增加热门图书的数量
 	if len(recommendations) < limit {
-		morePopular, err := s.gorseClient.GetPopular("", limit-len(recommendations))
+		morePopular, _, err := s.recommender.GetPopularRecommendations(ctx, category, limit-len(recommendations), popularCount)
 		if err == nil {
 			recommendations = append(recommendations, morePopular...)
+			popularCount += len(morePopular)
 		}
 	}
 
-	return recommendations[:minInt(len(recommendations), limit)], nil
+	return recommendations[:minInt(len(recommendations), limit)], popularCount, source, nil
 }
 
 // minInt 返回两个整数中的较小值
@@ -160,40 +407,125 @@ func minInt(a, b int) int {
 	return b
 }
 
-// GetPopularBooks 获取热门图书
-func (s *BookService) GetPopularBooks(limit int) ([]*model.BookInfo, error) {
-	// 从推荐系统获取热门图书
-	popularTitles, err := s.gorseClient.GetPopular("", limit)
-	if err != nil {
-		return nil, fmt.Errorf("获取热门图书失败: %v", err)
-	}
+// RecommenderStatus 返回各推荐引擎的状态，如Gorse的熔断状态（closed、open、half_open）、本地模型是否就绪
+func (s *BookService) RecommenderStatus() map[string]string {
+	return s.recommender.Status()
+}
+
+// InvalidateRecommendationCache 清空热门、最新、相似图书的候选列表缓存，返回清除的条目数
+func (s *BookService) InvalidateRecommendationCache() int {
+	return s.cache.invalidate()
+}
 
-	// 根据标题获取完整的图书信息
-	return s.getBooksByTitles(popularTitles)
+// GetPopularBooks 获取热门图书，推荐引擎给出的候选按分类和数量缓存
+func (s *BookService) GetPopularBooks(ctx context.Context, category string, limit int) ([]*model.RecommendedBook, error) {
+	// 从推荐引擎获取热门图书，过滤不可借图书后按需补取
+	return s.collect(s.cache.fetch(ctx, "popular|"+category, s.cacheTTL.popular, func(ctx context.Context) fetchFunc {
+		return func(n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+			ids, source, err := s.recommender.GetPopularRecommendations(ctx, category, n, offset)
+			if err != nil {
+				return nil, source, fmt.Errorf("获取热门图书失败: %w", err)
+			}
+			return ids, source, nil
+		}
+	}), limit)
+}
+
+// GetLatestBooks 获取最新图书，推荐引擎给出的候选按分类和数量缓存
+func (s *BookService) GetLatestBooks(ctx context.Context, category string, limit int) ([]*model.RecommendedBook, error) {
+	return s.collect(s.cache.fetch(ctx, "latest|"+category, s.cacheTTL.latest, func(ctx context.Context) fetchFunc {
+		return func(n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+			ids, source, err := s.recommender.GetLatestRecommendations(ctx, category, n, offset)
+			if err != nil {
+				return nil, source, fmt.Errorf("获取最新图书失败: %w", err)
+			}
+			return ids, source, nil
+		}
+	}), limit)
+}
+
+// GetSimilarBooks 获取相似图书，推荐引擎给出的候选按图书、分类和数量缓存
+func (s *BookService) GetSimilarBooks(ctx context.Context, bookID, category string, limit int) ([]*model.RecommendedBook, error) {
+	// 从推荐引擎获取相似图书，过滤不可借图书后按需补取
+	return s.collect(s.cache.fetch(ctx, "similar|"+bookID+"|"+category, s.cacheTTL.similar, func(ctx context.Context) fetchFunc {
+		return func(n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+			ids, source, err := s.recommender.GetSimilarItemRecommendations(ctx, bookID, category, n, offset)
+			if err != nil {
+				return nil, source, fmt.Errorf("获取相似图书失败: %w", err)
+			}
+			return ids, source, nil
+		}
+	}), limit)
 }
 
-// GetSimilarBooks 获取相似图书
-func (s *BookService) GetSimilarBooks(title string, limit int) ([]*model.BookInfo, error) {
-	// 从推荐系统获取相似图书
-	similarTitles, err := s.gorseClient.GetItemNeighbors(title, "", limit)
+// ListCategories 按中图法一级大类和二级类目统计馆藏数量，与发布到Gorse的物品分类一致
+func (s *BookService) ListCategories() ([]*model.CLCCategory, error) {
+	counts, err := s.bookRepo.CountByClassification()
 	if err != nil {
-		return nil, fmt.Errorf("获取相似图书失败: %v", err)
+		return nil, fmt.Errorf("统计图书分类失败: %v", err)
 	}
 
-	// 根据标题获取完整的图书信息
-	return s.getBooksByTitles(similarTitles)
+	classes := make(map[string]*model.CLCCategory)
+	subclasses := make(map[string]*model.CLCCategory)
+	for classificationNumber, count := range counts {
+		class := model.CLCTopClass(classificationNumber)
+		if class == "" {
+			continue
+		}
+		top, ok := classes[class]
+		if !ok {
+			top = &model.CLCCategory{Code: class, Name: model.CLCCategoryName(class)}
+			classes[class] = top
+		}
+		top.Count += count
+
+		subclass := model.CLCSubClass(classificationNumber)
+		if subclass == "" {
+			continue
+		}
+		sub, ok := subclasses[subclass]
+		if !ok {
+			sub = &model.CLCCategory{Code: subclass, Name: model.CLCCategoryName(subclass)}
+			subclasses[subclass] = sub
+			top.Subcategories = append(top.Subcategories, sub)
+		}
+		sub.Count += count
+	}
+
+	categories := make([]*model.CLCCategory, 0, len(classes))
+	for _, top := range classes {
+		sort.Slice(top.Subcategories, func(i, j int) bool {
+			return top.Subcategories[i].Code < top.Subcategories[j].Code
+		})
+		categories = append(categories, top)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].Code < categories[j].Code
+	})
+
+	return categories, nil
 }
 
-// getBooksByTitles 根据标题列表获取完整的图书信息
-func (s *BookService) getBooksByTitles(titles []string) ([]*model.BookInfo, error) {
-	if len(titles) == 0 {
+// getBooksByIDs 根据图书编号列表获取完整的图书信息，结果保持 bookIDs 的顺序
+func (s *BookService) getBooksByIDs(bookIDs []string) ([]*model.BookInfo, error) {
+	if len(bookIDs) == 0 {
 		return []*model.BookInfo{}, nil
 	}
 
-	books, _, err := s.bookRepo.BatchGetBooksByTitles(titles)
+	books, err := s.bookRepo.FindByBookIDs(bookIDs)
 	if err != nil {
 		return nil, fmt.Errorf("获取图书详细信息失败: %v", err)
 	}
 
-	return books, nil
+	byID := make(map[string]*model.BookInfo, len(books))
+	for _, book := range books {
+		byID[book.BookID] = book
+	}
+	ordered := make([]*model.BookInfo, 0, len(books))
+	for _, id := range bookIDs {
+		if book, ok := byID[id]; ok {
+			ordered = append(ordered, book)
+		}
+	}
+	return ordered, nil
 }
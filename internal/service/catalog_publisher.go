@@ -0,0 +1,212 @@
+package service
+
+import (
+	"fmt"
+	"library/internal/gorse"
+	"library/internal/model"
+	"library/internal/repository"
+	"log"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// publishBatchSize 每次批量写入Gorse的物品数
+const publishBatchSize = 500
+
+// CatalogPublisher 将馆藏图书同步为Gorse物品
+// 分类取自中图法分类号的一级、二级类目，标签包含作者、出版社和语种，时间戳取出版日期；丢失的图书设为隐藏
+type CatalogPublisher struct {
+	bookRepo    repository.BookRepository
+	gorseClient *gorse.Client
+}
+
+// NewCatalogPublisher 创建新的 CatalogPublisher 实例
+func NewCatalogPublisher(bookRepo repository.BookRepository, gorseClient *gorse.Client) *CatalogPublisher {
+	return &CatalogPublisher{
+		bookRepo:    bookRepo,
+		gorseClient: gorseClient,
+	}
+}
+
+// PublishPending 将上次成功发布之后变化的图书写入Gorse，并隐藏其间软删除的图书；全部成功后将发布进度推进到 until
+// 从未发布过或 full 为 true 时发布全部馆藏。失败时不推进进度，下次发布会重新覆盖这段时间内的变化
+// until 应不晚于本次发布读取数据之前的时间，避免遗漏发布过程中的修改
+func (p *CatalogPublisher) PublishPending(until time.Time, full bool) error {
+	since, err := p.bookRepo.LastPublishedAt()
+	if err != nil {
+		return fmt.Errorf("读取Gorse发布进度失败: %v", err)
+	}
+
+	if since == nil || full {
+		if _, err := p.PublishAll(); err != nil {
+			return err
+		}
+		// 全量发布后隐藏全部已软删除的图书
+		since = &time.Time{}
+	} else if _, err := p.PublishChangedSince(*since); err != nil {
+		return err
+	}
+	if _, err := p.HideDeletedSince(*since); err != nil {
+		return err
+	}
+
+	if err := p.bookRepo.SavePublishedAt(until); err != nil {
+		return fmt.Errorf("保存Gorse发布进度失败: %v", err)
+	}
+	return nil
+}
+
+// PublishAll 按主键顺序遍历全部馆藏并批量写入Gorse，返回写入的物品数
+func (p *CatalogPublisher) PublishAll() (int, error) {
+	published := 0
+	lastID := decimal.Zero
+	for {
+		books, err := p.bookRepo.ListAfterID(lastID, publishBatchSize)
+		if err != nil {
+			return published, fmt.Errorf("读取馆藏图书失败: %v", err)
+		}
+		if len(books) == 0 {
+			break
+		}
+
+		if err := p.PublishBooks(books); err != nil {
+			return published, err
+		}
+		published += len(books)
+		lastID = books[len(books)-1].ID
+	}
+
+	log.Printf("已同步 %d 本图书到Gorse", published)
+	return published, nil
+}
+
+// PublishChangedSince 将 since 之后新增或变化的图书写入Gorse，返回写入的物品数
+func (p *CatalogPublisher) PublishChangedSince(since time.Time) (int, error) {
+	published := 0
+	lastID := decimal.Zero
+	for {
+		books, err := p.bookRepo.ListUpdatedSince(since, lastID, publishBatchSize)
+		if err != nil {
+			return published, fmt.Errorf("读取变化的馆藏图书失败: %v", err)
+		}
+		if len(books) == 0 {
+			break
+		}
+
+		if err := p.PublishBooks(books); err != nil {
+			return published, err
+		}
+		published += len(books)
+		lastID = books[len(books)-1].ID
+	}
+
+	log.Printf("已同步 %d 本变化的图书到Gorse", published)
+	return published, nil
+}
+
+// HideDeletedSince 将 since 之后软删除的图书在Gorse中设为隐藏，返回处理的图书数
+func (p *CatalogPublisher) HideDeletedSince(since time.Time) (int, error) {
+	hidden := 0
+	lastID := decimal.Zero
+	for {
+		books, err := p.bookRepo.ListDeletedSince(since, lastID, publishBatchSize)
+		if err != nil {
+			return hidden, fmt.Errorf("读取已删除的馆藏图书失败: %v", err)
+		}
+		if len(books) == 0 {
+			break
+		}
+
+		bookIDs := make([]string, 0, len(books))
+		for _, book := range books {
+			bookIDs = append(bookIDs, book.BookID)
+		}
+		if err := p.HideBooks(bookIDs); err != nil {
+			return hidden, fmt.Errorf("隐藏已删除图书失败: %v", err)
+		}
+		hidden += len(books)
+		lastID = books[len(books)-1].ID
+	}
+
+	if hidden > 0 {
+		log.Printf("已在Gorse中隐藏 %d 本已删除的图书", hidden)
+	}
+	return hidden, nil
+}
+
+// HideBooks 将指定图书在Gorse中设为隐藏，保留历史反馈但不再出现在推荐结果中；Gorse中不存在的物品无需处理
+func (p *CatalogPublisher) HideBooks(bookIDs []string) error {
+	hidden := true
+	failed := 0
+	for _, bookID := range bookIDs {
+		if err := p.gorseClient.PatchItem(bookID, gorse.ItemPatch{IsHidden: &hidden}); err != nil && !gorse.IsNotFound(err) {
+			log.Printf("隐藏Gorse物品 %s 失败: %v", bookID, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d/%d 个物品隐藏失败", failed, len(bookIDs))
+	}
+	return nil
+}
+
+// PublishBooks 将指定图书写入Gorse，已存在的物品整体覆盖
+func (p *CatalogPublisher) PublishBooks(books []*model.BookInfo) error {
+	items := make([]gorse.Item, 0, len(books))
+	for _, book := range books {
+		if book.BookID == "" {
+			continue
+		}
+		items = append(items, bookToItem(book))
+	}
+
+	if err := p.gorseClient.InsertItems(items); err != nil {
+		return fmt.Errorf("同步图书到Gorse失败: %v", err)
+	}
+	return nil
+}
+
+// bookToItem 将馆藏图书转换为Gorse物品
+func bookToItem(book *model.BookInfo) gorse.Item {
+	timestamp := book.PublicationDate
+	if timestamp.IsZero() {
+		timestamp = book.CreatedAt
+	}
+
+	return gorse.Item{
+		ItemId:     book.BookID,
+		IsHidden:   book.Status == model.BookStatusLost,
+		Categories: bookCategories(book),
+		Timestamp:  timestamp.Format(time.RFC3339),
+		Labels:     bookLabels(book),
+		Comment:    book.Title,
+	}
+}
+
+// bookCategories 根据中图法分类号生成物品分类（一级大类与二级类目）
+func bookCategories(book *model.BookInfo) []string {
+	categories := make([]string, 0, 2)
+	if class := model.CLCTopClass(book.ClassificationNumber); class != "" {
+		categories = append(categories, class)
+	}
+	if subclass := model.CLCSubClass(book.ClassificationNumber); subclass != "" {
+		categories = append(categories, subclass)
+	}
+	return categories
+}
+
+// bookLabels 生成物品标签，与 AnalyzeUserInterests 的键保持相同前缀
+func bookLabels(book *model.BookInfo) []string {
+	labels := make([]string, 0, 3)
+	if book.PrimaryAuthor != "" {
+		labels = append(labels, "author:"+book.PrimaryAuthor)
+	}
+	if book.Publisher != "" {
+		labels = append(labels, "publisher:"+book.Publisher)
+	}
+	if book.LanguageCode != "" {
+		labels = append(labels, "language:"+book.LanguageCode)
+	}
+	return labels
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"library/internal/bookFetch"
+	"library/internal/model"
+	"library/internal/repository"
+	"log"
+	"sync"
+	"time"
+)
+
+// catalogSyncSource 馆藏同步任务在执行记录中的来源名称
+const catalogSyncSource = "catalog_books"
+
+// ErrSyncRunning 已有同步任务在执行
+var ErrSyncRunning = errors.New("已有馆藏同步任务在执行")
+
+// CatalogSyncService 馆藏同步任务
+// 定时触发和手动触发共用同一入口，同一时间只允许一个任务执行；每次执行都记录到 sync_runs
+type CatalogSyncService struct {
+	fetcher   *bookFetch.Fetcher
+	publisher *CatalogPublisher
+	runRepo   repository.SyncRunRepository
+	cache     RecommendationCacheInterface
+
+	ctx     context.Context
+	mu      sync.Mutex
+	running *model.SyncRun
+	wg      sync.WaitGroup
+}
+
+// NewCatalogSyncService 创建馆藏同步任务，ctx 取消时中断执行中的同步；同步写入馆藏后清空 cache
+func NewCatalogSyncService(ctx context.Context, fetcher *bookFetch.Fetcher, publisher *CatalogPublisher, runRepo repository.SyncRunRepository, cache RecommendationCacheInterface) *CatalogSyncService {
+	return &CatalogSyncService{
+		fetcher:   fetcher,
+		publisher: publisher,
+		runRepo:   runRepo,
+		cache:     cache,
+		ctx:       ctx,
+	}
+}
+
+// RecoverInterrupted 将上次进程退出时仍处于执行中的记录标记为失败
+func (s *CatalogSyncService) RecoverInterrupted() error {
+	n, err := s.runRepo.FailRunning(catalogSyncSource, "服务重启，任务中断")
+	if err != nil {
+		return fmt.Errorf("清理中断的同步记录失败: %v", err)
+	}
+	if n > 0 {
+		log.Printf("已将 %d 条中断的馆藏同步记录标记为失败", n)
+	}
+	return nil
+}
+
+// Trigger 异步启动一次同步并返回任务ID，已有任务在执行时返回该任务的ID和 ErrSyncRunning
+// full 为 true 时同步后将全部馆藏重新发布到Gorse，否则只发布上次成功发布之后的变化
+func (s *CatalogSyncService) Trigger(trigger model.SyncTrigger, full bool) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running != nil {
+		return s.running.ID, ErrSyncRunning
+	}
+	if err := s.ctx.Err(); err != nil {
+		return "", fmt.Errorf("服务正在关闭: %v", err)
+	}
+
+	run := &model.SyncRun{
+		Source:    catalogSyncSource,
+		Trigger:   trigger,
+		Status:    model.SyncRunRunning,
+		StartedAt: time.Now(),
+	}
+	if err := s.runRepo.Create(run); err != nil {
+		return "", fmt.Errorf("创建同步记录失败: %v", err)
+	}
+	s.running = run
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.execute(run, full)
+	}()
+	return run.ID, nil
+}
+
+// RunScheduled 供调度器调用，已有任务在执行时跳过本次
+func (s *CatalogSyncService) RunScheduled(ctx context.Context) {
+	if _, err := s.Trigger(model.SyncTriggerSchedule, false); err != nil {
+		log.Printf("跳过定时馆藏同步: %v", err)
+	}
+}
+
+// ListRuns 按开始时间倒序获取执行记录
+func (s *CatalogSyncService) ListRuns(limit int) ([]*model.SyncRun, error) {
+	return s.runRepo.List(catalogSyncSource, limit)
+}
+
+// Wait 等待执行中的同步结束，ctx 到期时放弃等待
+func (s *CatalogSyncService) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// execute 执行同步、发布变化到Gorse、清空推荐缓存并更新执行记录
+func (s *CatalogSyncService) execute(run *model.SyncRun, full bool) {
+	defer func() {
+		s.mu.Lock()
+		s.running = nil
+		s.mu.Unlock()
+	}()
+
+	log.Printf("开始馆藏同步任务 %s（%s）", run.ID, run.Trigger)
+	stats, err := s.fetcher.Sync(s.ctx)
+	if err == nil {
+		// 发布失败时本次记录为失败，发布进度不推进，下次同步重新发布
+		if err = s.publisher.PublishPending(stats.StartedAt, full); err != nil {
+			err = fmt.Errorf("发布到Gorse失败: %w", err)
+		}
+	}
+
+	if stats != nil {
+		run.Pages = stats.Pages
+		run.Fetched = stats.Fetched
+		run.Inserted = stats.Inserted
+		run.Updated = stats.Updated
+		run.Unchanged = stats.Unchanged
+		run.Skipped = stats.Skipped
+		run.Removed = stats.Removed
+
+		// 同步中途失败时已写入的部分同样生效
+		if n := s.cache.InvalidateRecommendationCache(); n > 0 {
+			log.Printf("馆藏同步后已清空 %d 条推荐缓存", n)
+		}
+	}
+	switch {
+	case err == nil:
+		run.Status = model.SyncRunSucceeded
+	case s.ctx.Err() != nil:
+		run.Status = model.SyncRunCanceled
+		run.Error = err.Error()
+	default:
+		run.Status = model.SyncRunFailed
+		run.Error = err.Error()
+	}
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+
+	if err := s.runRepo.Save(run); err != nil {
+		log.Printf("保存同步记录 %s 失败: %v", run.ID, err)
+	}
+	log.Printf("馆藏同步任务 %s 结束，状态: %s", run.ID, run.Status)
+}
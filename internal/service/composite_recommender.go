@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"library/internal/gorse"
+	"library/internal/model"
+	"log"
+	"sort"
+	"sync"
+)
+
+// rrfK 倒数排名融合的平滑常数，越大排名靠后的结果与靠前的差距越小
+const rrfK = 60
+
+// compositeMode 组合方式
+type compositeMode int
+
+const (
+	// compositeFailover 使用主引擎，不可用或出错时改用备用引擎
+	compositeFailover compositeMode = iota
+	// compositeBlend 同时请求两个引擎，按权重做倒数排名融合
+	compositeBlend
+)
+
+// recommendCall 对单个引擎发起的一次分页推荐请求
+type recommendCall func(r Recommender, n, offset int) ([]gorse.Score, model.RecommendSource, error)
+
+// CompositeRecommender 组合两个推荐引擎
+type CompositeRecommender struct {
+	primary   Recommender
+	secondary Recommender
+	mode      compositeMode
+	weight    float64 // blend 模式下主引擎的权重
+}
+
+// NewFailoverRecommender 创建主备切换的组合引擎
+func NewFailoverRecommender(primary, secondary Recommender) *CompositeRecommender {
+	return &CompositeRecommender{
+		primary:   primary,
+		secondary: secondary,
+		mode:      compositeFailover,
+	}
+}
+
+// NewBlendRecommender 创建融合两个引擎结果的组合引擎，weight 为主引擎的权重（0~1）
+func NewBlendRecommender(primary, secondary Recommender, weight float64) *CompositeRecommender {
+	return &CompositeRecommender{
+		primary:   primary,
+		secondary: secondary,
+		mode:      compositeBlend,
+		weight:    weight,
+	}
+}
+
+// GetPersonalizedRecommendations 获取个性化推荐
+func (c *CompositeRecommender) GetPersonalizedRecommendations(ctx context.Context, userID, category string, n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+	return c.run(ctx, n, offset, func(r Recommender, n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+		return r.GetPersonalizedRecommendations(ctx, userID, category, n, offset)
+	})
+}
+
+// GetPopularRecommendations 获取热门图书
+func (c *CompositeRecommender) GetPopularRecommendations(ctx context.Context, category string, n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+	return c.run(ctx, n, offset, func(r Recommender, n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+		return r.GetPopularRecommendations(ctx, category, n, offset)
+	})
+}
+
+// GetLatestRecommendations 获取最新图书
+func (c *CompositeRecommender) GetLatestRecommendations(ctx context.Context, category string, n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+	return c.run(ctx, n, offset, func(r Recommender, n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+		return r.GetLatestRecommendations(ctx, category, n, offset)
+	})
+}
+
+// GetSimilarItemRecommendations 获取相似图书
+func (c *CompositeRecommender) GetSimilarItemRecommendations(ctx context.Context, itemID, category string, n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+	return c.run(ctx, n, offset, func(r Recommender, n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+		return r.GetSimilarItemRecommendations(ctx, itemID, category, n, offset)
+	})
+}
+
+// RecordUserFeedback 将反馈写入两个引擎
+func (c *CompositeRecommender) RecordUserFeedback(userID, itemID, feedbackType string, timestamp int64, extra map[string]interface{}) error {
+	return errors.Join(
+		c.primary.RecordUserFeedback(userID, itemID, feedbackType, timestamp, extra),
+		c.secondary.RecordUserFeedback(userID, itemID, feedbackType, timestamp, extra),
+	)
+}
+
+// Available 任一引擎可用即可用
+func (c *CompositeRecommender) Available() bool {
+	return c.primary.Available() || c.secondary.Available()
+}
+
+// Status 合并两个引擎的状态
+func (c *CompositeRecommender) Status() map[string]string {
+	status := c.primary.Status()
+	for name, state := range c.secondary.Status() {
+		status[name] = state
+	}
+	return status
+}
+
+// Source 主备切换时为主引擎的推荐源，融合时为 blend
+func (c *CompositeRecommender) Source() model.RecommendSource {
+	if c.mode == compositeBlend {
+		return model.RecommendSourceBlend
+	}
+	return c.primary.Source()
+}
+
+// run 按组合方式执行请求
+func (c *CompositeRecommender) run(ctx context.Context, n, offset int, call recommendCall) ([]gorse.Score, model.RecommendSource, error) {
+	if c.mode == compositeBlend {
+		return c.blend(ctx, n, offset, call)
+	}
+	return c.failover(ctx, n, offset, call)
+}
+
+// failover 主引擎不可用时直接使用备用引擎，主引擎出错且备用引擎可用时改用备用引擎
+func (c *CompositeRecommender) failover(ctx context.Context, n, offset int, call recommendCall) ([]gorse.Score, model.RecommendSource, error) {
+	if !c.primary.Available() && c.secondary.Available() {
+		return call(c.secondary, n, offset)
+	}
+
+	scores, source, err := call(c.primary, n, offset)
+	if err == nil || ctx.Err() != nil || !c.secondary.Available() {
+		return scores, source, err
+	}
+	log.Printf("主推荐引擎不可用，改用备用推荐引擎: %v", err)
+	return call(c.secondary, n, offset)
+}
+
+// blend 并发请求两个引擎后按权重融合，一方不可用或出错时只使用另一方的结果
+// 融合后的排名与两个引擎各自的分页位置无关，因此每次都从头获取前 offset+n 个候选，融合后再截取当前页
+func (c *CompositeRecommender) blend(ctx context.Context, n, offset int, call recommendCall) ([]gorse.Score, model.RecommendSource, error) {
+	usePrimary, useSecondary := c.primary.Available(), c.secondary.Available()
+	if !usePrimary && !useSecondary {
+		usePrimary = true // 都不可用时仍请求主引擎，由其返回错误
+	}
+	if !usePrimary {
+		return call(c.secondary, n, offset)
+	}
+	if !useSecondary {
+		return call(c.primary, n, offset)
+	}
+
+	type result struct {
+		scores []gorse.Score
+		source model.RecommendSource
+		err    error
+	}
+	var primary, secondary result
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		primary.scores, primary.source, primary.err = call(c.primary, offset+n, 0)
+	}()
+	go func() {
+		defer wg.Done()
+		secondary.scores, secondary.source, secondary.err = call(c.secondary, offset+n, 0)
+	}()
+	wg.Wait()
+
+	switch {
+	case primary.err != nil && (secondary.err != nil || ctx.Err() != nil):
+		return nil, primary.source, primary.err
+	case primary.err != nil:
+		log.Printf("主推荐引擎不可用，只使用备用推荐引擎: %v", primary.err)
+		return pageScores(secondary.scores, n, offset), secondary.source, nil
+	case secondary.err != nil:
+		log.Printf("备用推荐引擎不可用，只使用主推荐引擎: %v", secondary.err)
+		return pageScores(primary.scores, n, offset), primary.source, nil
+	}
+	fused := fuseRankings(primary.scores, secondary.scores, c.weight)
+	return pageScores(fused, n, offset), model.RecommendSourceBlend, nil
+}
+
+// pageScores 截取 [offset, offset+n) 范围内的候选
+func pageScores(scores []gorse.Score, n, offset int) []gorse.Score {
+	if offset >= len(scores) {
+		return nil
+	}
+	return scores[offset:minInt(len(scores), offset+n)]
+}
+
+// fuseRankings 倒数排名融合：每个候选的分数为各列表中 weight/(rrfK+名次) 之和，按分数降序排列
+// 两个引擎的原始分数量纲不同，只使用名次
+func fuseRankings(primary, secondary []gorse.Score, weight float64) []gorse.Score {
+	fused := make(map[string]float64, len(primary)+len(secondary))
+	order := make([]string, 0, len(primary)+len(secondary))
+	add := func(scores []gorse.Score, w float64) {
+		for rank, score := range scores {
+			if _, ok := fused[score.Id]; !ok {
+				order = append(order, score.Id)
+			}
+			fused[score.Id] += w / float64(rrfK+rank+1)
+		}
+	}
+	add(primary, weight)
+	add(secondary, 1-weight)
+
+	result := make([]gorse.Score, 0, len(order))
+	for _, id := range order {
+		result = append(result, gorse.Score{Id: id, Score: fused[id]})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Score > result[j].Score
+	})
+	return result
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"library/internal/gorse"
+	"library/internal/model"
+	"reflect"
+	"testing"
+)
+
+// stubRecommender 按固定列表分页返回结果的推荐引擎
+type stubRecommender struct {
+	scores    []gorse.Score
+	source    model.RecommendSource
+	available bool
+	err       error
+	calls     int
+}
+
+func (r *stubRecommender) list(n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+	r.calls++
+	if r.err != nil {
+		return nil, r.source, r.err
+	}
+	return pageScores(r.scores, n, offset), r.source, nil
+}
+
+func (r *stubRecommender) GetPersonalizedRecommendations(ctx context.Context, userID, category string, n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+	return r.list(n, offset)
+}
+
+func (r *stubRecommender) GetPopularRecommendations(ctx context.Context, category string, n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+	return r.list(n, offset)
+}
+
+func (r *stubRecommender) GetLatestRecommendations(ctx context.Context, category string, n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+	return r.list(n, offset)
+}
+
+func (r *stubRecommender) GetSimilarItemRecommendations(ctx context.Context, itemID, category string, n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+	return r.list(n, offset)
+}
+
+func (r *stubRecommender) RecordUserFeedback(userID, itemID, feedbackType string, timestamp int64, extra map[string]interface{}) error {
+	return nil
+}
+
+func (r *stubRecommender) Available() bool { return r.available }
+func (r *stubRecommender) Status() map[string]string {
+	return map[string]string{string(r.source): "ok"}
+}
+func (r *stubRecommender) Source() model.RecommendSource { return r.source }
+
+// scoreList 生成 prefix0..prefix(n-1) 的候选列表，分数递减
+func scoreList(prefix string, n int) []gorse.Score {
+	scores := make([]gorse.Score, 0, n)
+	for i := 0; i < n; i++ {
+		scores = append(scores, gorse.Score{Id: fmt.Sprintf("%s%d", prefix, i), Score: float64(n - i)})
+	}
+	return scores
+}
+
+func scoreIDs(scores []gorse.Score) []string {
+	ids := make([]string, 0, len(scores))
+	for _, score := range scores {
+		ids = append(ids, score.Id)
+	}
+	return ids
+}
+
+func TestFuseRankings(t *testing.T) {
+	tests := []struct {
+		name      string
+		primary   []string
+		secondary []string
+		weight    float64
+		want      []string
+	}{
+		{"两边都出现的排在前面", []string{"a", "b", "c"}, []string{"c", "d"}, 0.5, []string{"c", "a", "b", "d"}},
+		{"主引擎权重较高", []string{"a", "b"}, []string{"c", "d"}, 0.7, []string{"a", "b", "c", "d"}},
+		{"备用引擎权重较高", []string{"a", "b"}, []string{"c", "d"}, 0.3, []string{"c", "d", "a", "b"}},
+		{"权重相同时按出现顺序", []string{"a"}, []string{"b"}, 0.5, []string{"a", "b"}},
+		{"只使用名次不使用原始分数", []string{"a", "b"}, []string{"b", "a"}, 0.5, []string{"a", "b"}},
+		{"一方为空", nil, []string{"a", "b"}, 0.7, []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toScores := func(ids []string) []gorse.Score {
+				scores := make([]gorse.Score, 0, len(ids))
+				for i, id := range ids {
+					scores = append(scores, gorse.Score{Id: id, Score: float64(1000 * (i + 1))})
+				}
+				return scores
+			}
+			got := scoreIDs(fuseRankings(toScores(tt.primary), toScores(tt.secondary), tt.weight))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fuseRankings = %v，期望 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageScores(t *testing.T) {
+	scores := scoreList("a", 5)
+	tests := []struct {
+		n, offset int
+		want      []string
+	}{
+		{2, 0, []string{"a0", "a1"}},
+		{2, 4, []string{"a4"}},
+		{2, 5, []string{}},
+		{10, 0, []string{"a0", "a1", "a2", "a3", "a4"}},
+	}
+	for _, tt := range tests {
+		if got := scoreIDs(pageScores(scores, tt.n, tt.offset)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("pageScores(%d, %d) = %v，期望 %v", tt.n, tt.offset, got, tt.want)
+		}
+	}
+}
+
+func TestBlendPagination(t *testing.T) {
+	primary := &stubRecommender{scores: scoreList("g", 20), source: model.RecommendSourceGorse, available: true}
+	secondary := &stubRecommender{scores: scoreList("l", 20), source: model.RecommendSourceLocal, available: true}
+	blend := NewBlendRecommender(primary, secondary, 0.5)
+
+	// 逐页读取得到的结果与一次读取全部融合结果一致，不重复也不遗漏
+	all, source, err := blend.GetPopularRecommendations(context.Background(), "", 40, 0)
+	if err != nil || source != model.RecommendSourceBlend || len(all) != 40 {
+		t.Fatalf("读取全部结果: %d 条，推荐源 %s，错误 %v", len(all), source, err)
+	}
+	var paged []gorse.Score
+	for offset := 0; offset < 40; offset += 7 {
+		page, _, err := blend.GetPopularRecommendations(context.Background(), "", 7, offset)
+		if err != nil {
+			t.Fatalf("读取 offset=%d 失败: %v", offset, err)
+		}
+		paged = append(paged, page...)
+	}
+	if !reflect.DeepEqual(scoreIDs(paged), scoreIDs(all)) {
+		t.Errorf("分页结果 %v 与整体结果 %v 不一致", scoreIDs(paged), scoreIDs(all))
+	}
+}
+
+func TestBlendDegrades(t *testing.T) {
+	tests := []struct {
+		name         string
+		primaryErr   error
+		secondaryErr error
+		primaryDown  bool
+		wantSource   model.RecommendSource
+		wantErr      bool
+	}{
+		{"两方正常", nil, nil, false, model.RecommendSourceBlend, false},
+		{"主引擎出错", errors.New("gorse down"), nil, false, model.RecommendSourceLocal, false},
+		{"备用引擎出错", nil, errors.New("not ready"), false, model.RecommendSourceGorse, false},
+		{"主引擎不可用", nil, nil, true, model.RecommendSourceLocal, false},
+		{"两方都出错", errors.New("gorse down"), errors.New("not ready"), false, model.RecommendSourceGorse, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &stubRecommender{scores: scoreList("g", 5), source: model.RecommendSourceGorse, available: !tt.primaryDown, err: tt.primaryErr}
+			secondary := &stubRecommender{scores: scoreList("l", 5), source: model.RecommendSourceLocal, available: true, err: tt.secondaryErr}
+			scores, source, err := NewBlendRecommender(primary, secondary, 0.7).GetPopularRecommendations(context.Background(), "", 3, 1)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("返回错误 %v，期望返回错误: %v", err, tt.wantErr)
+			}
+			if source != tt.wantSource {
+				t.Errorf("推荐源 = %s，期望 %s", source, tt.wantSource)
+			}
+			if !tt.wantErr && len(scores) != 3 {
+				t.Errorf("返回 %d 条结果，期望 3 条", len(scores))
+			}
+		})
+	}
+}
+
+func TestFailover(t *testing.T) {
+	tests := []struct {
+		name             string
+		primaryErr       error
+		primaryDown      bool
+		secondaryDown    bool
+		wantSource       model.RecommendSource
+		wantErr          bool
+		wantPrimaryCalls int
+	}{
+		{"主引擎正常", nil, false, false, model.RecommendSourceGorse, false, 1},
+		{"主引擎出错改用备用", errors.New("gorse down"), false, false, model.RecommendSourceLocal, false, 1},
+		{"主引擎不可用直接用备用", nil, true, false, model.RecommendSourceLocal, false, 0},
+		{"备用不可用时返回主引擎错误", errors.New("gorse down"), false, true, model.RecommendSourceGorse, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &stubRecommender{scores: scoreList("g", 5), source: model.RecommendSourceGorse, available: !tt.primaryDown, err: tt.primaryErr}
+			secondary := &stubRecommender{scores: scoreList("l", 5), source: model.RecommendSourceLocal, available: !tt.secondaryDown}
+			failover := NewFailoverRecommender(primary, secondary)
+			_, source, err := failover.GetPopularRecommendations(context.Background(), "", 3, 0)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("返回错误 %v，期望返回错误: %v", err, tt.wantErr)
+			}
+			if source != tt.wantSource {
+				t.Errorf("推荐源 = %s，期望 %s", source, tt.wantSource)
+			}
+			if primary.calls != tt.wantPrimaryCalls {
+				t.Errorf("主引擎调用 %d 次，期望 %d 次", primary.calls, tt.wantPrimaryCalls)
+			}
+			if failover.Source() != model.RecommendSourceGorse {
+				t.Errorf("主备切换引擎的推荐源应为主引擎的推荐源")
+			}
+		})
+	}
+}
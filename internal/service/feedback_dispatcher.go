@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"library/config"
+	"library/internal/gorse"
+	"library/internal/model"
+	"library/internal/repository"
+	"log"
+	"math/rand"
+	"slices"
+	"time"
+)
+
+// FeedbackDispatcher 反馈发件箱投递任务
+// 后台轮询 feedback_outbox，将反馈批量投递到Gorse，失败按指数退避重试，超过最大次数进入死信
+type FeedbackDispatcher struct {
+	outboxRepo   repository.OutboxRepository
+	behaviorRepo repository.BehaviorRepository
+	gorseClient  *gorse.Client
+	cfg          config.OutboxConfig
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewFeedbackDispatcher 创建新的 FeedbackDispatcher 实例
+func NewFeedbackDispatcher(outboxRepo repository.OutboxRepository, behaviorRepo repository.BehaviorRepository, gorseClient *gorse.Client, cfg config.OutboxConfig) *FeedbackDispatcher {
+	return &FeedbackDispatcher{
+		outboxRepo:   outboxRepo,
+		behaviorRepo: behaviorRepo,
+		gorseClient:  gorseClient,
+		cfg:          cfg,
+		wake:         make(chan struct{}, 1),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+}
+
+// Start 启动后台投递循环
+func (d *FeedbackDispatcher) Start() {
+	go d.run()
+}
+
+// Notify 通知投递任务有新的反馈入队，不阻塞调用方
+func (d *FeedbackDispatcher) Notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Shutdown 停止轮询并尽力排空到期的发件箱记录，ctx 到期后直接返回
+func (d *FeedbackDispatcher) Shutdown(ctx context.Context) error {
+	close(d.stop)
+	select {
+	case <-d.done:
+	case <-ctx.Done():
+		return fmt.Errorf("等待发件箱投递任务退出超时: %v", ctx.Err())
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("排空发件箱超时: %v", ctx.Err())
+		default:
+		}
+
+		n, err := d.dispatchOnce()
+		if err != nil {
+			return err
+		}
+		if n < d.cfg.BatchSize {
+			return nil
+		}
+	}
+}
+
+// RequeuePending 将尚未转发到Gorse且不在投递中的行为（死信或缺少发件箱记录）重新放入发件箱，返回入队条数
+func (d *FeedbackDispatcher) RequeuePending(limit int) (int, error) {
+	behaviors, err := d.behaviorRepo.ListRequeueable(limit)
+	if err != nil {
+		return 0, fmt.Errorf("获取待重放行为失败: %v", err)
+	}
+
+	entries := make([]*model.FeedbackOutbox, 0, len(behaviors))
+	for _, behavior := range behaviors {
+		entries = append(entries, model.NewFeedbackOutbox(behavior))
+	}
+	if err := d.outboxRepo.Enqueue(entries); err != nil {
+		return 0, fmt.Errorf("重放行为入队失败: %v", err)
+	}
+
+	d.Notify()
+	return len(entries), nil
+}
+
+// Stats 返回发件箱各状态的记录数
+func (d *FeedbackDispatcher) Stats() (map[model.OutboxStatus]int64, error) {
+	return d.outboxRepo.CountByStatus()
+}
+
+func (d *FeedbackDispatcher) run() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+
+		// 满批说明可能还有积压，继续投递直到不足一批
+		for {
+			n, err := d.dispatchOnce()
+			if err != nil {
+				log.Printf("发件箱投递失败: %v", err)
+				break
+			}
+			if n < d.cfg.BatchSize {
+				break
+			}
+			select {
+			case <-d.stop:
+				return
+			default:
+			}
+		}
+	}
+}
+
+// dispatchOnce 领取并投递一批到期记录，返回领取的条数
+func (d *FeedbackDispatcher) dispatchOnce() (int, error) {
+	// 熔断期间不领取，避免记录在Gorse恢复前耗尽重试次数进入死信
+	if d.gorseClient.CircuitState() == gorse.CircuitOpen {
+		return 0, nil
+	}
+
+	// 租期覆盖一次Gorse请求，进程崩溃时记录会在租期后被重新领取
+	entries, err := d.outboxRepo.ClaimDue(d.cfg.BatchSize, time.Minute)
+	if err != nil {
+		return 0, fmt.Errorf("领取发件箱记录失败: %v", err)
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	delivered := d.deliver(entries)
+	if len(delivered) == 0 {
+		return len(entries), nil
+	}
+
+	ids := make([]uint, 0, len(delivered))
+	behaviorIDs := make([]string, 0, len(delivered))
+	for _, entry := range delivered {
+		ids = append(ids, entry.ID)
+		behaviorIDs = append(behaviorIDs, entry.BehaviorID)
+	}
+	if err := d.behaviorRepo.MarkForwarded(behaviorIDs, time.Now()); err != nil {
+		log.Printf("标记用户行为转发状态失败: %v", err)
+	}
+	if err := d.outboxRepo.Delete(ids); err != nil {
+		return len(entries), fmt.Errorf("删除已投递发件箱记录失败: %v", err)
+	}
+	return len(entries), nil
+}
+
+// deliver 批量投递并返回投递成功的记录，失败的记录安排重试
+// Gorse 因请求内容拒绝整批时二分拆批重新投递，只让内容有误的记录计入失败，避免一条错误数据拖累整批进入死信
+func (d *FeedbackDispatcher) deliver(entries []*model.FeedbackOutbox) []*model.FeedbackOutbox {
+	feedbacks := make([]gorse.Feedback, 0, len(entries))
+	for _, entry := range entries {
+		feedbacks = append(feedbacks, gorse.Feedback{
+			FeedbackType: entry.FeedbackType,
+			UserId:       entry.UserID,
+			ItemId:       entry.ItemID,
+			Timestamp:    entry.Timestamp,
+			Extra:        entry.ExtraMap(),
+		})
+	}
+
+	err := d.gorseClient.InsertFeedbacks(feedbacks)
+	if err == nil {
+		return entries
+	}
+	if len(entries) > 1 && gorse.IsRejected(err) {
+		mid := len(entries) / 2
+		return slices.Concat(d.deliver(entries[:mid]), d.deliver(entries[mid:]))
+	}
+	d.markFailed(entries, err)
+	return nil
+}
+
+// markFailed 按指数退避安排重试，超过最大次数或内容被Gorse拒绝的记录进入死信
+func (d *FeedbackDispatcher) markFailed(entries []*model.FeedbackOutbox, cause error) {
+	now := time.Now()
+	rejected := gorse.IsRejected(cause)
+	for _, entry := range entries {
+		entry.Attempts++
+		entry.LastError = cause.Error()
+		if rejected {
+			entry.Status = model.OutboxStatusDead
+			log.Printf("反馈 %s 被Gorse拒绝，进入死信: %v", entry.BehaviorID, cause)
+		} else if entry.Attempts >= d.cfg.MaxAttempts {
+			entry.Status = model.OutboxStatusDead
+			log.Printf("反馈 %s 重试 %d 次后进入死信: %v", entry.BehaviorID, entry.Attempts, cause)
+		} else {
+			entry.NextAttemptAt = now.Add(d.backoff(entry.Attempts))
+		}
+		if err := d.outboxRepo.MarkFailed(entry); err != nil {
+			log.Printf("更新发件箱记录 %d 失败: %v", entry.ID, err)
+		}
+	}
+}
+
+// backoff 计算第 attempts 次失败后的退避时间（指数增长，带随机抖动）
+func (d *FeedbackDispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.BaseBackoff
+	for i := 1; i < attempts && delay < d.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.cfg.MaxBackoff {
+		delay = d.cfg.MaxBackoff
+	}
+	// 在 [delay/2, delay] 区间内抖动，避免集中重试
+	half := int64(delay / 2)
+	if half <= 0 {
+		return delay
+	}
+	return time.Duration(half + rand.Int63n(half+1))
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"library/config"
+	"library/internal/gorse"
+	"library/internal/model"
+	"library/internal/repository"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// stubOutboxRepo 记录 MarkFailed 调用的发件箱仓储
+type stubOutboxRepo struct {
+	repository.OutboxRepository
+	failed []*model.FeedbackOutbox
+}
+
+func (r *stubOutboxRepo) MarkFailed(entry *model.FeedbackOutbox) error {
+	r.failed = append(r.failed, entry)
+	return nil
+}
+
+func newTestDispatcher(outbox repository.OutboxRepository, gorseClient *gorse.Client) *FeedbackDispatcher {
+	return NewFeedbackDispatcher(outbox, nil, gorseClient, config.OutboxConfig{
+		BatchSize:   100,
+		MaxAttempts: 3,
+		BaseBackoff: time.Second,
+		MaxBackoff:  10 * time.Second,
+	})
+}
+
+func TestDispatcherBackoff(t *testing.T) {
+	d := newTestDispatcher(nil, nil)
+	tests := []struct {
+		attempts int
+		max      time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second},
+		{20, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("第%d次失败", tt.attempts), func(t *testing.T) {
+			// 抖动后落在 [max/2, max] 区间
+			for i := 0; i < 100; i++ {
+				if got := d.backoff(tt.attempts); got < tt.max/2 || got > tt.max {
+					t.Fatalf("backoff(%d) = %s，期望在 [%s, %s] 之间", tt.attempts, got, tt.max/2, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestDispatcherMarkFailed(t *testing.T) {
+	tests := []struct {
+		name       string
+		attempts   int
+		cause      error
+		wantStatus model.OutboxStatus
+	}{
+		{"未达最大次数时安排重试", 0, errors.New("timeout"), model.OutboxStatusPending},
+		{"服务端错误安排重试", 1, &gorse.APIError{StatusCode: 503}, model.OutboxStatusPending},
+		{"达到最大次数进入死信", 2, errors.New("timeout"), model.OutboxStatusDead},
+		{"内容被拒绝直接进入死信", 0, &gorse.APIError{StatusCode: 400}, model.OutboxStatusDead},
+		{"认证失败安排重试", 0, &gorse.APIError{StatusCode: 401}, model.OutboxStatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outbox := &stubOutboxRepo{}
+			d := newTestDispatcher(outbox, nil)
+			entry := &model.FeedbackOutbox{BehaviorID: "1", Status: model.OutboxStatusPending, Attempts: tt.attempts}
+			before := time.Now()
+			d.markFailed([]*model.FeedbackOutbox{entry}, tt.cause)
+
+			if len(outbox.failed) != 1 {
+				t.Fatalf("MarkFailed 调用 %d 次，期望 1 次", len(outbox.failed))
+			}
+			if entry.Attempts != tt.attempts+1 || entry.LastError != tt.cause.Error() {
+				t.Errorf("重试次数 = %d、错误 = %q", entry.Attempts, entry.LastError)
+			}
+			if entry.Status != tt.wantStatus {
+				t.Errorf("状态 = %s，期望 %s", entry.Status, tt.wantStatus)
+			}
+			if entry.Status == model.OutboxStatusPending && !entry.NextAttemptAt.After(before) {
+				t.Errorf("重试时间 %s 未顺延", entry.NextAttemptAt)
+			}
+		})
+	}
+}
+
+func TestDispatcherDeliverIsolatesRejected(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		bad           map[string]bool
+		wantDelivered int
+		wantDead      int
+		wantRetry     int
+	}{
+		{"全部成功", http.StatusBadRequest, nil, 10, 0, 0},
+		{"一条内容有误", http.StatusBadRequest, map[string]bool{"6": true}, 9, 1, 0},
+		{"两条内容有误", http.StatusUnprocessableEntity, map[string]bool{"0": true, "9": true}, 8, 2, 0},
+		{"服务端错误整批重试", http.StatusServiceUnavailable, map[string]bool{"6": true}, 0, 0, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var feedbacks []gorse.Feedback
+				if err := json.NewDecoder(r.Body).Decode(&feedbacks); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				for _, feedback := range feedbacks {
+					if tt.bad[feedback.ItemId] {
+						w.WriteHeader(tt.status)
+						return
+					}
+				}
+				w.Write([]byte(`{"RowAffected":1}`))
+			}))
+			defer server.Close()
+
+			outbox := &stubOutboxRepo{}
+			d := newTestDispatcher(outbox, gorse.NewClient(server.URL, ""))
+			entries := make([]*model.FeedbackOutbox, 0, 10)
+			for i := 0; i < 10; i++ {
+				id := fmt.Sprint(i)
+				entries = append(entries, &model.FeedbackOutbox{ID: uint(i), BehaviorID: id, ItemID: id, Status: model.OutboxStatusPending})
+			}
+
+			delivered := d.deliver(entries)
+			if len(delivered) != tt.wantDelivered {
+				t.Errorf("投递成功 %d 条，期望 %d 条", len(delivered), tt.wantDelivered)
+			}
+			for _, entry := range delivered {
+				if tt.bad[entry.ItemID] {
+					t.Errorf("有误的记录 %s 被视为投递成功", entry.ItemID)
+				}
+			}
+			dead, retry := 0, 0
+			for _, entry := range outbox.failed {
+				if entry.Status == model.OutboxStatusDead {
+					dead++
+				} else {
+					retry++
+				}
+			}
+			if dead != tt.wantDead || retry != tt.wantRetry {
+				t.Errorf("死信 %d 条、重试 %d 条，期望 %d、%d", dead, retry, tt.wantDead, tt.wantRetry)
+			}
+		})
+	}
+}
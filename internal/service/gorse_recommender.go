@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"library/internal/gorse"
+	"library/internal/model"
+)
+
+// GorseRecommender 基于Gorse的推荐引擎
+type GorseRecommender struct {
+	client *gorse.Client
+}
+
+// NewGorseRecommender 创建Gorse推荐引擎
+func NewGorseRecommender(client *gorse.Client) *GorseRecommender {
+	return &GorseRecommender{client: client}
+}
+
+// GetPersonalizedRecommendations 获取个性化推荐，Gorse中还没有该用户时返回空结果
+func (r *GorseRecommender) GetPersonalizedRecommendations(ctx context.Context, userID, category string, n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+	scores, err := r.client.GetRecommendContext(ctx, userID, category, n, offset)
+	if gorse.IsNotFound(err) {
+		return nil, model.RecommendSourceGorse, nil
+	}
+	return scores, model.RecommendSourceGorse, err
+}
+
+// GetPopularRecommendations 获取热门图书
+func (r *GorseRecommender) GetPopularRecommendations(ctx context.Context, category string, n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+	scores, err := r.client.GetPopularContext(ctx, category, n, offset)
+	return scores, model.RecommendSourceGorse, err
+}
+
+// GetLatestRecommendations 获取最新图书
+func (r *GorseRecommender) GetLatestRecommendations(ctx context.Context, category string, n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+	scores, err := r.client.GetLatestContext(ctx, category, n, offset)
+	return scores, model.RecommendSourceGorse, err
+}
+
+// GetSimilarItemRecommendations 获取相似图书
+func (r *GorseRecommender) GetSimilarItemRecommendations(ctx context.Context, itemID, category string, n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+	scores, err := r.client.GetItemNeighborsContext(ctx, itemID, category, n, offset)
+	return scores, model.RecommendSourceGorse, err
+}
+
+// RecordUserFeedback 直接写入一条反馈，业务行为应通过发件箱投递
+func (r *GorseRecommender) RecordUserFeedback(userID, itemID, feedbackType string, timestamp int64, extra map[string]interface{}) error {
+	return r.client.InsertFeedback(feedbackType, userID, itemID, timestamp, extra)
+}
+
+// Available 熔断器打开时不可用
+func (r *GorseRecommender) Available() bool {
+	return r.client.CircuitState() != gorse.CircuitOpen
+}
+
+// Status 返回熔断状态（closed、open、half_open）
+func (r *GorseRecommender) Status() map[string]string {
+	return map[string]string{string(model.RecommendSourceGorse): r.client.CircuitState().String()}
+}
+
+// Source 返回 gorse
+func (r *GorseRecommender) Source() model.RecommendSource {
+	return model.RecommendSourceGorse
+}
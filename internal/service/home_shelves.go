@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"library/internal/model"
+	"sync"
+)
+
+const (
+	// homeAnchorLookback 查找最近阅读图书时回看的行为条数
+	homeAnchorLookback = 50
+	// homeOverfetchFactor 栏目间去重前每个栏目多取的倍数
+	homeOverfetchFactor = 2
+)
+
+// shelfSpec 首页栏目定义
+type shelfSpec struct {
+	id          string
+	name        string
+	description string
+	fetch       func(ctx context.Context, limit int) ([]*model.RecommendedBook, error)
+}
+
+// GetHomeShelves 并行组装首页的多个推荐栏目
+// 栏目依次为：为你推荐、热门图书、最新上架、与最近阅读相似、分类精选；
+// 同一本书只出现在排序靠前的栏目中，单个栏目超时或失败不影响其他栏目
+func (s *BookService) GetHomeShelves(ctx context.Context, userID, category string, limit int) ([]*model.RecommendationCategory, error) {
+	anchor := s.lastReadBook(userID)
+	if category == "" && anchor != nil {
+		category = model.CLCSubClass(anchor.ClassificationNumber)
+		if category == "" {
+			category = model.CLCTopClass(anchor.ClassificationNumber)
+		}
+	}
+
+	var specs []shelfSpec
+	if userID != "" {
+		specs = append(specs, shelfSpec{
+			id:          "for-you",
+			name:        "为你推荐",
+			description: "基于您的借阅与浏览行为的个性化推荐",
+			fetch: func(ctx context.Context, n int) ([]*model.RecommendedBook, error) {
+				return s.GetRecommendations(ctx, userID, "", n)
+			},
+		})
+	}
+	specs = append(specs,
+		shelfSpec{
+			id:          "popular",
+			name:        "热门图书",
+			description: "近期最受读者欢迎的图书",
+			fetch: func(ctx context.Context, n int) ([]*model.RecommendedBook, error) {
+				return s.GetPopularBooks(ctx, "", n)
+			},
+		},
+		shelfSpec{
+			id:          "latest",
+			name:        "最新上架",
+			description: "最新出版入藏的图书",
+			fetch: func(ctx context.Context, n int) ([]*model.RecommendedBook, error) {
+				return s.GetLatestBooks(ctx, "", n)
+			},
+		},
+	)
+	if anchor != nil {
+		specs = append(specs, shelfSpec{
+			id:          "similar-to-last-read",
+			name:        "与《" + anchor.Title + "》相似",
+			description: "与您最近阅读的图书相似的图书",
+			fetch: func(ctx context.Context, n int) ([]*model.RecommendedBook, error) {
+				return s.GetSimilarBooks(ctx, anchor.BookID, "", n)
+			},
+		})
+	}
+	if category != "" {
+		name := model.CLCCategoryName(category)
+		if name == "" {
+			name = category
+		}
+		specs = append(specs, shelfSpec{
+			id:          "category-" + category,
+			name:        name + "精选",
+			description: fmt.Sprintf("中图法 %s 类下的热门图书", category),
+			fetch: func(ctx context.Context, n int) ([]*model.RecommendedBook, error) {
+				return s.GetPopularBooks(ctx, category, n)
+			},
+		})
+	}
+
+	shelves := make([]*model.RecommendationCategory, len(specs))
+	var wg sync.WaitGroup
+	for i, spec := range specs {
+		wg.Add(1)
+		go func(i int, spec shelfSpec) {
+			defer wg.Done()
+			shelves[i] = s.runShelf(ctx, spec, limit*homeOverfetchFactor)
+		}(i, spec)
+	}
+	wg.Wait()
+
+	// 按栏目顺序跨栏目去重
+	seen := make(map[string]bool)
+	failed := 0
+	for _, shelf := range shelves {
+		if shelf.Error != "" {
+			failed++
+		}
+		books := make([]*model.RecommendedBook, 0, limit)
+		for _, book := range shelf.Books {
+			if len(books) >= limit {
+				break
+			}
+			if seen[book.BookID] {
+				continue
+			}
+			seen[book.BookID] = true
+			book.Rank = len(books) + 1
+			books = append(books, book)
+		}
+		shelf.Books = books
+		shelf.Total = len(books)
+	}
+
+	if failed == len(shelves) {
+		return nil, fmt.Errorf("所有首页栏目均获取失败: %s", shelves[0].Error)
+	}
+	return shelves, nil
+}
+
+// runShelf 在超时限制内获取单个栏目，超时或请求取消后中止对Gorse的调用并返回空栏目
+func (s *BookService) runShelf(ctx context.Context, spec shelfSpec, limit int) *model.RecommendationCategory {
+	shelf := &model.RecommendationCategory{
+		ID:          spec.id,
+		Name:        spec.name,
+		Description: spec.description,
+		Books:       []*model.RecommendedBook{},
+	}
+
+	type result struct {
+		books []*model.RecommendedBook
+		err   error
+	}
+	shelfCtx, cancel := context.WithTimeout(ctx, s.shelfTimeout)
+	defer cancel()
+
+	done := make(chan result, 1)
+	go func() {
+		books, err := spec.fetch(shelfCtx, limit)
+		done <- result{books: books, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			shelf.Error = r.err.Error()
+		} else {
+			shelf.Books = r.books
+			if len(r.books) > 0 {
+				shelf.Source = r.books[0].Source
+			}
+		}
+	case <-shelfCtx.Done():
+		if ctx.Err() != nil {
+			shelf.Error = "请求已取消"
+		} else {
+			shelf.Error = fmt.Sprintf("栏目获取超时（%s）", s.shelfTimeout)
+		}
+	}
+	return shelf
+}
+
+// lastReadBook 返回用户最近阅读的图书，没有阅读记录时取最近交互的图书
+func (s *BookService) lastReadBook(userID string) *model.BookInfo {
+	if userID == "" {
+		return nil
+	}
+
+	behaviors, err := s.behaviorRepo.ListByUser(userID, homeAnchorLookback)
+	if err != nil || len(behaviors) == 0 {
+		return nil
+	}
+
+	anchor := behaviors[0]
+	for _, behavior := range behaviors {
+		if behavior.FeedbackType == string(model.BehaviorRead) {
+			anchor = behavior
+			break
+		}
+	}
+	if anchor.BookID == "" {
+		return nil
+	}
+
+	book, err := s.bookRepo.GetBookByBookID(anchor.BookID)
+	if err != nil {
+		return nil
+	}
+	return book
+}
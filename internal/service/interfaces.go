@@ -1,17 +1,24 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"library/internal/gorse"
 	"library/internal/model"
+	"time"
 )
 
 // UserBehaviorRequest 用户行为请求
 type UserBehaviorRequest struct {
 	UserID          string                 `json:"user_id"`
-	BookTitle       string                 `json:"book_title"`
+	BookID          string                 `json:"book_id,omitempty"`    // 图书编号，优先使用
+	BookTitle       string                 `json:"book_title,omitempty"` // 图书标题，仅在未提供 book_id 时用于兼容旧客户端
 	BehaviorType    string                 `json:"behavior_type"`
 	StayTimeSeconds *int                   `json:"stay_time_seconds,omitempty"`
 	ReadTimeMinutes *int                   `json:"read_time_minutes,omitempty"`
+	Element         string                 `json:"element,omitempty"`      // 交互的元素
+	Position        *model.Position        `json:"position,omitempty"`     // 点击位置
+	ScrollDepth     *int                   `json:"scroll_depth,omitempty"` // 滚动深度(%)
 	Extra           map[string]interface{} `json:"extra,omitempty"`
 }
 
@@ -20,8 +27,8 @@ func (r *UserBehaviorRequest) Validate() error {
 	if r.UserID == "" {
 		return fmt.Errorf("user_id is required")
 	}
-	if r.BookTitle == "" {
-		return fmt.Errorf("book_title is required")
+	if r.BookID == "" && r.BookTitle == "" {
+		return fmt.Errorf("book_id or book_title is required")
 	}
 	if r.BehaviorType == "" {
 		return fmt.Errorf("behavior_type is required")
@@ -46,29 +53,66 @@ func (r *UserBehaviorRequest) Validate() error {
 	return nil
 }
 
+// BehaviorItemResult 批量行为记录中单条请求的处理结果
+type BehaviorItemResult struct {
+	Index   int    `json:"index"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BatchBehaviorResult 批量行为记录结果
+type BatchBehaviorResult struct {
+	Total     int                  `json:"total"`
+	Succeeded int                  `json:"succeeded"`
+	Failed    int                  `json:"failed"`
+	Results   []BehaviorItemResult `json:"results"`
+}
+
 // BookServiceInterface 图书服务接口
 // 定义了图书推荐系统的核心业务能力
 type BookServiceInterface interface {
 	// 统一的用户行为记录接口
 	RecordUserBehavior(req *UserBehaviorRequest) error
+	RecordUserBehaviors(reqs []*UserBehaviorRequest) (*BatchBehaviorResult, error)
+
+	// 推荐获取，category 为中图法一级大类或二级类目代码，为空表示不限分类
+	// ctx 通常为请求上下文，读者断开或服务关闭时中止对Gorse的调用
+	GetRecommendations(ctx context.Context, userID, category string, limit int) ([]*model.RecommendedBook, error)
+	GetPopularBooks(ctx context.Context, category string, limit int) ([]*model.RecommendedBook, error)
+	GetSimilarBooks(ctx context.Context, bookID, category string, limit int) ([]*model.RecommendedBook, error)
+	GetLatestBooks(ctx context.Context, category string, limit int) ([]*model.RecommendedBook, error)
 
-	// 推荐获取
-	GetRecommendations(userID string, limit int) ([]*model.BookInfo, error)
-	GetPopularBooks(limit int) ([]*model.BookInfo, error)
-	GetSimilarBooks(title string, limit int) ([]*model.BookInfo, error)
+	// 各推荐引擎的状态
+	RecommenderStatus() map[string]string
+
+	// 首页多栏目推荐
+	GetHomeShelves(ctx context.Context, userID, category string, limit int) ([]*model.RecommendationCategory, error)
+
+	// 获取可用的推荐分类及馆藏数量
+	ListCategories() ([]*model.CLCCategory, error)
+
+	// 根据图书编号或标题解析出图书编号
+	ResolveBookID(bookID, title string) (string, error)
+	SearchBooks(query *model.BookSearchQuery) (*model.BookSearchResponse, error)
+	GetBookDetail(ctx context.Context, id string) (*model.BookDetail, error)
+	GetBookDetailByBarcode(ctx context.Context, barcode string) (*model.BookDetail, error)
+	BatchGetBooks(req *model.BatchBookRequest) (*model.BatchBookResponse, error)
 }
 
 // RecommendationServiceInterface 推荐服务接口
-// 专门处理推荐算法相关的业务逻辑
+// 推荐引擎只给出按推荐顺序排列的候选图书编号及实际给出结果的推荐源，图书信息和可借过滤由 BookService 处理
 type RecommendationServiceInterface interface {
-	// 获取个性化推荐
-	GetPersonalizedRecommendations(userID string, limit int) ([]*model.BookInfo, error)
+	// 获取个性化推荐，引擎中没有该读者时返回空结果
+	GetPersonalizedRecommendations(ctx context.Context, userID, category string, n, offset int) ([]gorse.Score, model.RecommendSource, error)
 
 	// 获取热门推荐
-	GetPopularRecommendations(limit int) ([]*model.BookInfo, error)
+	GetPopularRecommendations(ctx context.Context, category string, n, offset int) ([]gorse.Score, model.RecommendSource, error)
+
+	// 获取最新图书
+	GetLatestRecommendations(ctx context.Context, category string, n, offset int) ([]gorse.Score, model.RecommendSource, error)
 
 	// 获取相似物品推荐
-	GetSimilarItemRecommendations(itemID string, limit int) ([]*model.BookInfo, error)
+	GetSimilarItemRecommendations(ctx context.Context, itemID, category string, n, offset int) ([]gorse.Score, model.RecommendSource, error)
 
 	// 记录用户反馈
 	RecordUserFeedback(userID, itemID, feedbackType string, timestamp int64, extra map[string]interface{}) error
@@ -89,3 +133,32 @@ type BehaviorTrackingServiceInterface interface {
 	// 分析用户兴趣
 	AnalyzeUserInterests(userID string) (map[string]float64, error)
 }
+
+// CatalogSyncServiceInterface 馆藏同步任务接口
+type CatalogSyncServiceInterface interface {
+	Trigger(trigger model.SyncTrigger, full bool) (string, error)
+	ListRuns(limit int) ([]*model.SyncRun, error)
+}
+
+// BookAdminServiceInterface 馆员馆藏维护接口
+type BookAdminServiceInterface interface {
+	GetBook(bookID string) (*model.BookInfo, error)
+	CreateBook(req *model.BookCreateRequest) (*model.BookInfo, error)
+	UpdateBook(bookID string, req *model.BookUpdateRequest) (*model.BookInfo, error)
+	DeleteBook(bookID string, updatedAt *time.Time) error
+}
+
+// FeedbackOutboxInterface 反馈发件箱管理接口
+type FeedbackOutboxInterface interface {
+	// 将尚未转发到Gorse的行为（包括死信）重新入队，返回入队条数
+	RequeuePending(limit int) (int, error)
+
+	// 各状态的记录数
+	Stats() (map[model.OutboxStatus]int64, error)
+}
+
+// RecommendationCacheInterface 推荐结果缓存管理接口
+type RecommendationCacheInterface interface {
+	// 清空缓存，返回清除的条目数
+	InvalidateRecommendationCache() int
+}
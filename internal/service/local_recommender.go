@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"library/internal/fallback"
+	"library/internal/gorse"
+	"library/internal/model"
+	"library/internal/repository"
+)
+
+// LocalRecommender 基于本地行为数据和馆藏表的推荐引擎
+type LocalRecommender struct {
+	engine   *fallback.Engine
+	bookRepo repository.BookRepository
+}
+
+// NewLocalRecommender 创建本地推荐引擎
+func NewLocalRecommender(engine *fallback.Engine, bookRepo repository.BookRepository) *LocalRecommender {
+	return &LocalRecommender{
+		engine:   engine,
+		bookRepo: bookRepo,
+	}
+}
+
+// GetPersonalizedRecommendations 以读者最近交互的图书为种子推荐，读者没有交互记录时返回空结果
+func (r *LocalRecommender) GetPersonalizedRecommendations(ctx context.Context, userID, category string, n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+	scores, err := r.engine.Recommend(userID, category, n, offset)
+	return scores, model.RecommendSourceLocal, err
+}
+
+// GetPopularRecommendations 按衰减热度获取热门图书
+func (r *LocalRecommender) GetPopularRecommendations(ctx context.Context, category string, n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+	scores, err := r.engine.Popular(category, n, offset)
+	return scores, model.RecommendSourceLocal, err
+}
+
+// GetLatestRecommendations 从馆藏表按出版日期获取最新图书，分数为时间戳，与Gorse一致
+func (r *LocalRecommender) GetLatestRecommendations(ctx context.Context, category string, n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+	books, err := r.bookRepo.ListLatest(category, n, offset)
+	if err != nil {
+		return nil, model.RecommendSourceLocal, err
+	}
+
+	scores := make([]gorse.Score, 0, len(books))
+	for _, book := range books {
+		timestamp := book.PublicationDate
+		if timestamp.IsZero() {
+			timestamp = book.CreatedAt
+		}
+		scores = append(scores, gorse.Score{Id: book.BookID, Score: float64(timestamp.Unix())})
+	}
+	return scores, model.RecommendSourceLocal, nil
+}
+
+// GetSimilarItemRecommendations 按共同读者获取相似图书
+func (r *LocalRecommender) GetSimilarItemRecommendations(ctx context.Context, itemID, category string, n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+	scores, err := r.engine.Similar(itemID, category, n, offset)
+	return scores, model.RecommendSourceLocal, err
+}
+
+// RecordUserFeedback 本地模型直接读取 user_behaviors，反馈在下次重建时自动纳入，无需单独记录
+func (r *LocalRecommender) RecordUserFeedback(userID, itemID, feedbackType string, timestamp int64, extra map[string]interface{}) error {
+	return nil
+}
+
+// Available 模型完成首次计算后可用
+func (r *LocalRecommender) Available() bool {
+	return r.engine.Ready()
+}
+
+// Status 返回模型状态（ready、not_ready）
+func (r *LocalRecommender) Status() map[string]string {
+	status := "not_ready"
+	if r.engine.Ready() {
+		status = "ready"
+	}
+	return map[string]string{string(model.RecommendSourceLocal): status}
+}
+
+// Source 返回 local
+func (r *LocalRecommender) Source() model.RecommendSource {
+	return model.RecommendSourceLocal
+}
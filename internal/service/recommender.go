@@ -0,0 +1,44 @@
+package service
+
+import (
+	"fmt"
+	"library/config"
+	"library/internal/fallback"
+	"library/internal/gorse"
+	"library/internal/model"
+	"library/internal/repository"
+)
+
+// Recommender 可替换的推荐引擎，BookService 只通过该接口获取候选，更换或组合引擎无需修改调用方
+type Recommender interface {
+	RecommendationServiceInterface
+
+	// Available 引擎当前是否可用（如Gorse熔断、本地模型未就绪时不可用），组合引擎据此跳过不可用的一方
+	Available() bool
+
+	// Status 各底层引擎的状态，用于健康检查
+	Status() map[string]string
+
+	// Source 引擎正常时结果的推荐源，结果来自其他推荐源说明处于降级状态
+	Source() model.RecommendSource
+}
+
+// NewRecommender 按配置创建推荐引擎，所选引擎需要本地推荐模型而 local 为 nil 时返回错误
+func NewRecommender(cfg config.RecommendConfig, gorseClient *gorse.Client, local *fallback.Engine, bookRepo repository.BookRepository) (Recommender, error) {
+	if cfg.UsesLocal() && local == nil {
+		return nil, fmt.Errorf("推荐引擎 %s 需要本地推荐模型", cfg.Backend)
+	}
+
+	switch cfg.Backend {
+	case config.RecommendBackendGorse:
+		return NewGorseRecommender(gorseClient), nil
+	case config.RecommendBackendLocal:
+		return NewLocalRecommender(local, bookRepo), nil
+	case config.RecommendBackendFailover:
+		return NewFailoverRecommender(NewGorseRecommender(gorseClient), NewLocalRecommender(local, bookRepo)), nil
+	case config.RecommendBackendBlend:
+		return NewBlendRecommender(NewGorseRecommender(gorseClient), NewLocalRecommender(local, bookRepo), cfg.BlendWeight), nil
+	default:
+		return nil, fmt.Errorf("未知的推荐引擎: %s", cfg.Backend)
+	}
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"library/internal/gorse"
+	"library/internal/model"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// resultCache 推荐引擎候选列表的读穿缓存
+// 只缓存推荐引擎给出的图书编号和分数，图书信息和可借过滤每次请求时重新计算，借阅状态和删除立即生效；
+// 同一键的并发未命中合并为一次加载（singleflight），加载失败或来自降级推荐源的结果不缓存
+type resultCache struct {
+	maxEntries int
+	source     model.RecommendSource // 只缓存来自该推荐源的结果，即推荐引擎正常时的推荐源
+
+	mu         sync.Mutex
+	entries    map[string]cacheEntry
+	calls      map[string]*cacheCall
+	generation uint64 // 每次失效递增，失效前发起的加载不再写入缓存
+}
+
+// cacheEntry 缓存项，scores 只读
+type cacheEntry struct {
+	scores    []gorse.Score
+	source    model.RecommendSource
+	expiresAt time.Time
+}
+
+// cacheCall 执行中的加载，等待者在 done 关闭后读取结果
+type cacheCall struct {
+	done   chan struct{}
+	scores []gorse.Score
+	source model.RecommendSource
+	err    error
+}
+
+// newResultCache 创建缓存，maxEntries 为最多保留的缓存项数，只缓存来自 source 的结果
+func newResultCache(maxEntries int, source model.RecommendSource) *resultCache {
+	return &resultCache{
+		maxEntries: maxEntries,
+		source:     source,
+		entries:    make(map[string]cacheEntry),
+		calls:      make(map[string]*cacheCall),
+	}
+}
+
+// fetch 为 build 生成的 fetchFunc 加上缓存，每一页按 key、n、offset 分别缓存；ttl 不大于0时不缓存也不合并
+// 合并的加载不随发起者的 ctx 取消而中止，避免一个请求断开导致所有等待者失败，对推荐引擎的调用仍受各自超时限制
+func (c *resultCache) fetch(ctx context.Context, key string, ttl time.Duration, build func(ctx context.Context) fetchFunc) fetchFunc {
+	if ttl <= 0 {
+		return build(ctx)
+	}
+	fetch := build(context.WithoutCancel(ctx))
+	return func(n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+		pageKey := key + "|" + strconv.Itoa(n) + "|" + strconv.Itoa(offset)
+		return c.get(ctx, pageKey, ttl, func() ([]gorse.Score, model.RecommendSource, error) {
+			return fetch(n, offset)
+		})
+	}
+}
+
+// get 命中未过期的缓存时直接返回，否则加载并缓存 ttl
+// 合并的加载在独立的 goroutine 中执行，等待者可因自身 ctx 取消而提前返回
+func (c *resultCache) get(ctx context.Context, key string, ttl time.Duration, load func() ([]gorse.Score, model.RecommendSource, error)) ([]gorse.Score, model.RecommendSource, error) {
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok {
+		if time.Now().Before(entry.expiresAt) {
+			c.mu.Unlock()
+			return entry.scores, entry.source, nil
+		}
+		delete(c.entries, key)
+	}
+	call, ok := c.calls[key]
+	if !ok {
+		call = &cacheCall{done: make(chan struct{})}
+		c.calls[key] = call
+		go c.load(key, ttl, c.generation, call, load)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.scores, call.source, call.err
+	case <-ctx.Done():
+		return nil, "", ctx.Err()
+	}
+}
+
+// load 执行加载，成功、来自正常推荐源且期间没有失效时写入缓存
+func (c *resultCache) load(key string, ttl time.Duration, generation uint64, call *cacheCall, load func() ([]gorse.Score, model.RecommendSource, error)) {
+	call.scores, call.source, call.err = load()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.calls, key)
+	close(call.done)
+	if call.err != nil || call.source != c.source || generation != c.generation {
+		return
+	}
+	c.makeRoom()
+	c.entries[key] = cacheEntry{
+		scores:    call.scores,
+		source:    call.source,
+		expiresAt: time.Now().Add(ttl),
+	}
+}
+
+// makeRoom 缓存已满时先清理过期项，仍然已满则随机淘汰，需持有锁
+func (c *resultCache) makeRoom() {
+	if len(c.entries) < c.maxEntries {
+		return
+	}
+	now := time.Now()
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+	for key := range c.entries {
+		if len(c.entries) < c.maxEntries {
+			break
+		}
+		delete(c.entries, key)
+	}
+}
+
+// invalidate 清空缓存，返回清除的缓存项数；执行中的加载完成后不会写入缓存
+func (c *resultCache) invalidate() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	count := len(c.entries)
+	c.entries = make(map[string]cacheEntry)
+	c.generation++
+	return count
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"library/internal/gorse"
+	"library/internal/model"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingLoad 返回固定结果并统计调用次数的加载函数
+func countingLoad(calls *int32, source model.RecommendSource, err error) func() ([]gorse.Score, model.RecommendSource, error) {
+	return func() ([]gorse.Score, model.RecommendSource, error) {
+		atomic.AddInt32(calls, 1)
+		return []gorse.Score{{Id: "a"}}, source, err
+	}
+}
+
+func TestResultCacheStoresOnlyPrimarySource(t *testing.T) {
+	tests := []struct {
+		name       string
+		source     model.RecommendSource
+		err        error
+		wantLoads  int32
+		wantCached int
+	}{
+		{"正常推荐源的结果缓存", model.RecommendSourceGorse, nil, 1, 1},
+		{"降级推荐源的结果不缓存", model.RecommendSourceLocal, nil, 2, 0},
+		{"加载失败不缓存", model.RecommendSourceGorse, errors.New("timeout"), 2, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newResultCache(10, model.RecommendSourceGorse)
+			var calls int32
+			for i := 0; i < 2; i++ {
+				cache.get(context.Background(), "popular|", time.Minute, countingLoad(&calls, tt.source, tt.err))
+			}
+			if calls != tt.wantLoads {
+				t.Errorf("加载 %d 次，期望 %d 次", calls, tt.wantLoads)
+			}
+			if got := cache.invalidate(); got != tt.wantCached {
+				t.Errorf("缓存 %d 项，期望 %d 项", got, tt.wantCached)
+			}
+		})
+	}
+}
+
+func TestResultCacheCoalesces(t *testing.T) {
+	cache := newResultCache(10, model.RecommendSourceGorse)
+	release := make(chan struct{})
+	var calls int32
+	load := func() ([]gorse.Score, model.RecommendSource, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return []gorse.Score{{Id: "a"}}, model.RecommendSourceGorse, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scores, _, err := cache.get(context.Background(), "popular|", time.Minute, load)
+			if err != nil || len(scores) != 1 {
+				t.Errorf("get 返回 %v, %v", scores, err)
+			}
+		}()
+	}
+	// 等待所有请求进入等待后再完成加载
+	for {
+		cache.mu.Lock()
+		waiting := len(cache.calls) == 1
+		cache.mu.Unlock()
+		if waiting {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("并发未命中加载 %d 次，期望合并为 1 次", calls)
+	}
+}
+
+func TestResultCacheInvalidateDuringLoad(t *testing.T) {
+	cache := newResultCache(10, model.RecommendSourceGorse)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cache.get(context.Background(), "popular|", time.Minute, func() ([]gorse.Score, model.RecommendSource, error) {
+			close(started)
+			<-release
+			return []gorse.Score{{Id: "stale"}}, model.RecommendSourceGorse, nil
+		})
+	}()
+	<-started
+	cache.invalidate()
+	close(release)
+	<-done
+
+	// 失效前发起的加载结果不写入缓存
+	var calls int32
+	cache.get(context.Background(), "popular|", time.Minute, countingLoad(&calls, model.RecommendSourceGorse, nil))
+	if calls != 1 {
+		t.Errorf("失效前的加载结果被写入了缓存")
+	}
+}
+
+func TestResultCacheExpiry(t *testing.T) {
+	cache := newResultCache(10, model.RecommendSourceGorse)
+	var calls int32
+	load := countingLoad(&calls, model.RecommendSourceGorse, nil)
+	cache.get(context.Background(), "popular|", time.Minute, load)
+
+	cache.mu.Lock()
+	entry := cache.entries["popular|"]
+	entry.expiresAt = time.Now().Add(-time.Second)
+	cache.entries["popular|"] = entry
+	cache.mu.Unlock()
+
+	cache.get(context.Background(), "popular|", time.Minute, load)
+	if calls != 2 {
+		t.Errorf("过期后加载 %d 次，期望重新加载", calls)
+	}
+}
+
+func TestResultCacheWaiterCanceled(t *testing.T) {
+	cache := newResultCache(10, model.RecommendSourceGorse)
+	release := make(chan struct{})
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := cache.get(ctx, "popular|", time.Minute, func() ([]gorse.Score, model.RecommendSource, error) {
+		<-release
+		return nil, model.RecommendSourceGorse, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("等待者取消时返回 %v，期望 context.Canceled", err)
+	}
+}
+
+func TestResultCacheMaxEntries(t *testing.T) {
+	cache := newResultCache(2, model.RecommendSourceGorse)
+	var calls int32
+	for _, key := range []string{"a", "b", "c", "d"} {
+		cache.get(context.Background(), key, time.Minute, countingLoad(&calls, model.RecommendSourceGorse, nil))
+	}
+	if got := cache.invalidate(); got > 2 {
+		t.Errorf("缓存 %d 项，超过上限 2", got)
+	}
+}
+
+func TestResultCacheFetchPages(t *testing.T) {
+	cache := newResultCache(10, model.RecommendSourceGorse)
+	var calls int32
+	build := func(ctx context.Context) fetchFunc {
+		return func(n, offset int) ([]gorse.Score, model.RecommendSource, error) {
+			atomic.AddInt32(&calls, 1)
+			return pageScores(scoreList("g", 10), n, offset), model.RecommendSourceGorse, nil
+		}
+	}
+
+	fetch := cache.fetch(context.Background(), "popular|", time.Minute, build)
+	first, _, _ := fetch(4, 0)
+	fetch(4, 0)
+	second, _, _ := fetch(4, 4)
+	if calls != 2 {
+		t.Errorf("加载 %d 次，期望每页各加载 1 次", calls)
+	}
+	if first[0].Id != "g0" || second[0].Id != "g4" {
+		t.Errorf("不同分页共用了缓存: %v %v", first, second)
+	}
+
+	// ttl 不大于0时不缓存
+	uncached := cache.fetch(context.Background(), "latest|", 0, build)
+	uncached(4, 0)
+	uncached(4, 0)
+	if calls != 4 {
+		t.Errorf("ttl 为0时加载 %d 次，期望每次都加载", calls)
+	}
+}
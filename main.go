@@ -4,15 +4,19 @@ import (
 	"context"
 	"fmt"
 	"library/internal/bookFetch"
+	"library/internal/circulation"
+	"library/internal/dataplatform"
+	"library/internal/fallback"
+	"library/internal/scheduler"
 	"log"
 	"net/http"
-	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
 	"library/api"
 	"library/config"
+	"library/internal/gorse"
 	"library/internal/model"
 	"library/internal/repository"
 	"library/internal/service"
@@ -26,18 +30,8 @@ func main() {
 	// 加载配置
 	cfg := config.NewConfig()
 
-	// 构建 PostgreSQL 连接字符串
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.User,
-		cfg.Database.Password,
-		cfg.Database.DBName,
-		cfg.Database.SSLMode,
-	)
-
 	// 初始化数据库连接
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(cfg.Database.DSN()), &gorm.Config{})
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
@@ -52,21 +46,122 @@ func main() {
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
 	// 自动迁移数据库表
-	err = db.AutoMigrate(&model.BookInfo{}, &model.UserBehavior{})
+	err = db.AutoMigrate(&model.BookInfo{}, &model.UserBehavior{}, &model.FeedbackOutbox{}, &model.SyncCheckpoint{}, &model.SyncRun{}, &model.CirculationRecord{})
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
+	repository.EnsureSearchIndexes(db)
+	if err := repository.EnsureLocalIDSequence(db); err != nil {
+		log.Fatal("Failed to create local book id sequence:", err)
+	}
 
 	// 初始化依赖
 	bookRepo := repository.NewBookRepository(db)
-	bookService := service.NewBookService(bookRepo, cfg.Gorse.Endpoint, cfg.Gorse.APIKey)
+	behaviorRepo := repository.NewBehaviorRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	syncRunRepo := repository.NewSyncRunRepository(db)
+	circulationRepo := repository.NewCirculationRepository(db)
+
+	// SIGINT/SIGTERM 时取消，用于停止定时任务和中断执行中的同步
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	gorseClient := gorse.NewClientWithOptions(cfg.Gorse.Endpoint, cfg.Gorse.APIKey, gorse.Options{
+		Timeouts: gorse.Timeouts{
+			Request:   cfg.Gorse.RequestTimeout,
+			Feedback:  cfg.Gorse.FeedbackTimeout,
+			Recommend: cfg.Gorse.RecommendTimeout,
+			Popular:   cfg.Gorse.PopularTimeout,
+			Neighbors: cfg.Gorse.NeighborsTimeout,
+			Latest:    cfg.Gorse.LatestTimeout,
+		},
+		MaxRetries:       cfg.Gorse.MaxRetries,
+		RetryBackoff:     cfg.Gorse.RetryBackoff,
+		BreakerThreshold: cfg.Gorse.BreakerThreshold,
+		BreakerCooldown:  cfg.Gorse.BreakerCooldown,
+	})
+
+	// 启动反馈发件箱投递任务
+	dispatcher := service.NewFeedbackDispatcher(outboxRepo, behaviorRepo, gorseClient, cfg.Outbox)
+	dispatcher.Start()
+
+	catalogPublisher := service.NewCatalogPublisher(bookRepo, gorseClient)
+
+	// 本地推荐：根据 user_behaviors 计算，供 local、failover、blend 推荐引擎使用
+	var localRecommender *fallback.Engine
+	if cfg.Recommend.UsesLocal() {
+		localRecommender = fallback.NewEngine(behaviorRepo, circulationRepo, bookRepo, cfg.Fallback)
+		go localRecommender.RunScheduled(ctx)
+	}
+
+	// 按配置选择推荐引擎
+	recommender, err := service.NewRecommender(cfg.Recommend, gorseClient, localRecommender, bookRepo)
+	if err != nil {
+		log.Fatal("Invalid recommender config:", err)
+	}
+
+	bookService, err := service.NewBookService(bookRepo, behaviorRepo, dispatcher, cfg.Recommend, recommender)
+	if err != nil {
+		log.Fatal("Invalid recommendation config:", err)
+	}
+	behaviorService := service.NewBehaviorTrackingService(behaviorRepo, bookRepo)
+	bookAdminService := service.NewBookAdminService(bookRepo, catalogPublisher, bookService)
+
+	// 定时任务按 cron 表达式执行
+	jobs := scheduler.New()
+	if localRecommender != nil {
+		schedule, err := scheduler.Parse(cfg.Fallback.RefreshSchedule)
+		if err != nil {
+			log.Fatal("Invalid fallback refresh schedule:", err)
+		}
+		jobs.Add("fallback_refresh", schedule, localRecommender.RunScheduled)
+	}
+
+	// 馆藏同步任务：定时执行，也可通过管理接口手动触发
+	var catalogSync service.CatalogSyncServiceInterface
+	var catalogSyncService *service.CatalogSyncService
+	if cfg.Catalog.Enabled() {
+		platform, err := dataplatform.NewClient(cfg.Catalog)
+		if err != nil {
+			log.Fatal("Failed to create data platform client:", err)
+		}
+		fetcher := bookFetch.NewFetcher(db, bookRepo, platform, cfg.Catalog)
+		schedule, err := scheduler.Parse(cfg.Catalog.SyncSchedule)
+		if err != nil {
+			log.Fatal("Invalid catalog sync schedule:", err)
+		}
+
+		catalogSyncService = service.NewCatalogSyncService(ctx, fetcher, catalogPublisher, syncRunRepo, bookService)
+		if err := catalogSyncService.RecoverInterrupted(); err != nil {
+			log.Println(err)
+		}
+		jobs.Add("catalog_sync", schedule, catalogSyncService.RunScheduled)
+		catalogSync = catalogSyncService
+
+		// 流通记录作为借阅反馈导入，并同步图书借阅状态
+		if cfg.Circulation.Enabled() {
+			circulationSchedule, err := scheduler.Parse(cfg.Circulation.SyncSchedule)
+			if err != nil {
+				log.Fatal("Invalid circulation sync schedule:", err)
+			}
+			importer := circulation.NewImporter(circulationRepo, bookRepo, platform, cfg.Circulation, dispatcher.Notify)
+			jobs.Add("circulation_sync", circulationSchedule, func(ctx context.Context) {
+				if _, err := importer.Sync(ctx); err != nil {
+					log.Println("流通记录同步失败:", err)
+				}
+			})
+		}
+	}
+	jobs.Start(ctx)
 
 	// 创建处理器
 	unifiedHandler := api.NewUnifiedHandler(bookService)
+	userHandler := api.NewUserHandler(behaviorService)
 	bookHandler := api.NewBookHandler(bookService) // 保留用于兼容性
+	adminHandler := api.NewAdminHandler(catalogSync, bookAdminService, bookService, dispatcher)
 
 	// 设置路由
-	mux := routes.SetupRoutes(unifiedHandler, bookHandler)
+	mux := routes.SetupRoutes(unifiedHandler, userHandler, bookHandler, adminHandler, cfg.Admin.Token)
 
 	// 创建服务器
 	server := &http.Server{
@@ -77,11 +172,6 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
-	go func() {
-		log.Println("启动图书数据定时同步任务...")
-		bookFetch.TimelyFetchAndSaveBooks(db)
-	}()
-
 	// 启动服务器（非阻塞）
 	go func() {
 		log.Printf("Server starting on port %s", cfg.Server.Port)
@@ -91,17 +181,33 @@ func main() {
 	}()
 
 	// 等待中断信号以优雅关闭服务器
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	<-ctx.Done()
+	stop()
 	log.Println("Shutting down server...")
 
 	// 优雅关闭服务器，等待现有连接完成
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
-	if err := server.Shutdown(ctx); err != nil {
+	if err := server.Shutdown(shutdownCtx); err != nil {
 		log.Fatal("Server forced to shutdown:", err)
 	}
 
+	// 等待定时任务退出和执行中的同步中断（断点已保存，下次从断点继续）
+	if err := jobs.Wait(shutdownCtx); err != nil {
+		log.Println("Scheduler shutdown:", err)
+	}
+	if catalogSyncService != nil {
+		if err := catalogSyncService.Wait(shutdownCtx); err != nil {
+			log.Println("Catalog sync shutdown:", err)
+		}
+	}
+
+	// 排空反馈发件箱，未投递的记录保留在表中，下次启动后继续投递
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), cfg.Outbox.DrainTimeout)
+	defer drainCancel()
+	if err := dispatcher.Shutdown(drainCtx); err != nil {
+		log.Println("Feedback dispatcher shutdown:", err)
+	}
+
 	log.Println("Server exited")
 }
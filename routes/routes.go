@@ -1,7 +1,10 @@
 package routes
 
 import (
+	"crypto/subtle"
 	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -10,7 +13,7 @@ import (
 )
 
 // SetupRoutes 设置API路由
-func SetupRoutes(unifiedHandler *api.UnifiedHandler, bookHandler *api.BookHandler) *gin.Engine {
+func SetupRoutes(unifiedHandler *api.UnifiedHandler, userHandler *api.UserHandler, bookHandler *api.BookHandler, adminHandler *api.AdminHandler, adminToken string) *gin.Engine {
 	router := gin.Default()
 
 	// 添加中间件
@@ -25,6 +28,16 @@ func SetupRoutes(unifiedHandler *api.UnifiedHandler, bookHandler *api.BookHandle
 	{
 		// 用户行为追踪
 		v1.POST("/behavior/track", unifiedHandler.TrackUserBehavior)
+		v1.POST("/behavior/batch", unifiedHandler.BatchTrackUserBehavior)
+
+		// 馆藏检索与图书详情
+		books := v1.Group("/books")
+		{
+			books.GET("/search", unifiedHandler.SearchBooks)
+			books.POST("/batch", unifiedHandler.BatchGetBooks)
+			books.GET("/barcode/:barcode", unifiedHandler.GetBookDetailByBarcode)
+			books.GET("/:id", unifiedHandler.GetBookDetail)
+		}
 
 		// 推荐系统
 		recommendations := v1.Group("/recommendations")
@@ -32,9 +45,36 @@ func SetupRoutes(unifiedHandler *api.UnifiedHandler, bookHandler *api.BookHandle
 			recommendations.GET("/personal", unifiedHandler.GetPersonalizedRecommendations)
 			recommendations.GET("/popular", unifiedHandler.GetPopularBooks)
 			recommendations.GET("/similar", unifiedHandler.GetSimilarBooks)
+			recommendations.GET("/categories", unifiedHandler.ListCategories)
+			recommendations.GET("/home", unifiedHandler.GetHomePage)
+		}
+
+		// 读者行为历史与兴趣分析
+		users := v1.Group("/users")
+		{
+			users.GET("/:id/history", userHandler.GetUserHistory)
+			users.GET("/:id/interests", userHandler.GetUserInterests)
 		}
 	}
 
+	// 管理接口，需携带管理令牌
+	admin := router.Group("/admin", adminAuth(adminToken))
+	{
+		admin.POST("/sync/catalog", adminHandler.TriggerCatalogSync)
+		admin.GET("/sync/runs", adminHandler.ListSyncRuns)
+		admin.POST("/cache/invalidate", adminHandler.InvalidateRecommendationCache)
+
+		// 反馈发件箱
+		admin.GET("/outbox/stats", adminHandler.GetOutboxStats)
+		admin.POST("/outbox/requeue", adminHandler.RequeueOutbox)
+
+		// 馆藏维护
+		admin.POST("/books", adminHandler.CreateBook)
+		admin.GET("/books/:id", adminHandler.GetBook)
+		admin.PATCH("/books/:id", adminHandler.UpdateBook)
+		admin.DELETE("/books/:id", adminHandler.DeleteBook)
+	}
+
 	// 兼容旧版本API（标记为废弃，逐步迁移）
 	deprecated := router.Group("/deprecated")
 	{
@@ -55,7 +95,7 @@ func setupMiddleware(router *gin.Engine) {
 	// CORS中间件
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"*"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
@@ -80,3 +120,19 @@ func setupMiddleware(router *gin.Engine) {
 	// 恢复中间件
 	router.Use(gin.Recovery())
 }
+
+// adminAuth 校验管理令牌（Authorization: Bearer <token>），未配置令牌时拒绝所有请求
+func adminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "管理接口未启用"})
+			return
+		}
+		provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "管理令牌无效"})
+			return
+		}
+		c.Next()
+	}
+}